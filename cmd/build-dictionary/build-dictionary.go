@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/hunspell"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/normalise"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/reader"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+type options struct {
+	Input        string  `json:"input"`
+	Affixes      string  `json:"affixes,omitempty"`
+	ProperNouns  bool    `json:"properNouns"`
+	Output       string  `json:"output"`
+	MinLength    int     `json:"minLength"`
+	MaxLength    int     `json:"maxLength"`
+	Include      string  `json:"include,omitempty"`
+	Exclude      string  `json:"exclude,omitempty"`
+	Allowlist    string  `json:"allowlist,omitempty"`
+	Denylist     string  `json:"denylist,omitempty"`
+	Dedupe       bool    `json:"dedupe"`
+	Sort         bool    `json:"sort"`
+	MinFrequency float64 `json:"minFrequency"`
+	Normalise    bool    `json:"normalise"`
+}
+
+type fileDigest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+type filterCount struct {
+	Filter   string `json:"filter"`
+	Rejected int    `json:"rejected"`
+}
+
+/**
+Records how an output file was produced so it can be rebuilt and checked later
+ */
+type manifest struct {
+	Input         fileDigest            `json:"input"`
+	Affixes       *fileDigest           `json:"affixes,omitempty"`
+	Output        fileDigest            `json:"output"`
+	Allowlist     *fileDigest           `json:"allowlist,omitempty"`
+	Denylist      *fileDigest           `json:"denylist,omitempty"`
+	Options       options               `json:"options"`
+	WordsRead     int                   `json:"wordsRead"`
+	WordsWritten  int                   `json:"wordsWritten"`
+	Filters       []filterCount         `json:"filters"`
+	Normalisation []normalise.Rejection `json:"normalisation,omitempty"`
+}
+
+type filter struct {
+	name string
+	keep func(word string) bool
+}
+
+/**
+Builds the list of filters a word must pass, in the order they are applied
+ */
+func buildFilters(opts options, frequencies map[string]float64) ([]filter, error) {
+	var filters []filter
+
+	if opts.MinLength > 0 || opts.MaxLength > 0 {
+		filters = append(filters, filter{"length", func(word string) bool {
+			length := utf8.RuneCountInString(word)
+			return length >= opts.MinLength && (opts.MaxLength <= 0 || length <= opts.MaxLength)
+		}})
+	}
+
+	if opts.Include != "" {
+		include, err := regexp.Compile(opts.Include)
+		if err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+		filters = append(filters, filter{"include", include.MatchString})
+	}
+
+	if opts.Exclude != "" {
+		exclude, err := regexp.Compile(opts.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		filters = append(filters, filter{"exclude", func(word string) bool {
+			return !exclude.MatchString(word)
+		}})
+	}
+
+	if opts.Allowlist != "" {
+		allowed, err := reader.ReadWordSet(context.Background(), opts.Allowlist)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter{"allowlist", func(word string) bool {
+			return allowed[word]
+		}})
+	}
+
+	if opts.Denylist != "" {
+		denied, err := reader.ReadWordSet(context.Background(), opts.Denylist)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter{"denylist", func(word string) bool {
+			return !denied[word]
+		}})
+	}
+
+	if opts.MinFrequency > 0 {
+		filters = append(filters, filter{"frequency", func(word string) bool {
+			return frequencies[word] >= opts.MinFrequency
+		}})
+	}
+
+	return filters, nil
+}
+
+func hashFile(filename string) (fileDigest, error) {
+	fileHandle, err := os.Open(filename)
+	if err != nil {
+		return fileDigest{}, err
+	}
+	defer fileHandle.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, fileHandle); err != nil {
+		return fileDigest{}, err
+	}
+
+	return fileDigest{filename, hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+/**
+Reads the input word list, expanding it with its .aff file first when it's a Hunspell dictionary
+ */
+func readInput(ctx context.Context, opts options, cb func(string) error) error {
+	if opts.Affixes == "" {
+		return reader.ReadFileContext(ctx, opts.Input, cb)
+	}
+
+	stream, err := hunspell.Open(ctx, opts.Input, opts.Affixes, hunspell.Options{DropProperNouns: !opts.ProperNouns})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return reader.Read(ctx, stream, cb)
+}
+
+func optionalDigest(filename string) (*fileDigest, error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	digest, err := hashFile(filename)
+	return &digest, err
+}
+
+/**
+Reads the input and returns the words that pass every filter, along with the manifest describing the run.
+A word's frequency is the sum of its TSV frequency column, lines without one count as 1
+ */
+func buildDictionary(ctx context.Context, opts options) ([]string, manifest, error) {
+	result := manifest{Options: opts}
+
+	var pipeline *normalise.Pipeline
+	if opts.Normalise {
+		pipeline = normalise.English()
+	}
+
+	var words []string
+	frequencies := make(map[string]float64)
+
+	err := readInput(ctx, opts, func(line string) error {
+		result.WordsRead++
+
+		entry, err := reader.ParseEntry(line)
+		if err != nil {
+			return err
+		}
+
+		collect := func(word string) error {
+			if entry.Frequency > 0 {
+				frequencies[word] += entry.Frequency
+			} else {
+				frequencies[word]++
+			}
+			words = append(words, word)
+			return nil
+		}
+
+		if pipeline != nil {
+			return pipeline.Filter(collect)(entry.Word)
+		}
+		return collect(entry.Word)
+	})
+	if err != nil {
+		return nil, result, err
+	}
+
+	filters, err := buildFilters(opts, frequencies)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rejected := make([]int, len(filters))
+	seen := make(map[string]bool)
+	var output []string
+
+	for _, word := range words {
+		keep := true
+
+		for i, f := range filters {
+			if !f.keep(word) {
+				rejected[i]++
+				keep = false
+				break
+			}
+		}
+
+		if !keep {
+			continue
+		}
+
+		if opts.Dedupe {
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+		}
+
+		output = append(output, word)
+	}
+
+	if opts.Sort {
+		sort.Strings(output)
+	}
+
+	for i, f := range filters {
+		result.Filters = append(result.Filters, filterCount{f.name, rejected[i]})
+	}
+	if pipeline != nil {
+		result.Normalisation = pipeline.Rejections()
+	}
+	result.WordsWritten = len(output)
+
+	return output, result, nil
+}
+
+func writeWords(filename string, words []string) (err error) {
+	fileHandle, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := fileHandle.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	writer := bufio.NewWriter(fileHandle)
+	for i, word := range words {
+		if i > 0 {
+			writer.WriteString("\n")
+		}
+		writer.WriteString(word)
+	}
+
+	return writer.Flush()
+}
+
+func writeManifest(filename string, result manifest) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+func main() {
+	var opts options
+	var manifestFile string
+
+	flag.StringVar(&opts.Input, "input", "./words_no-names-or-places.txt", "source word list, may be compressed")
+	flag.StringVar(&opts.Affixes, "aff", "", "Hunspell .aff file, when set input is the matching .dic")
+	flag.BoolVar(&opts.ProperNouns, "proper-nouns", false, "keep capitalised Hunspell stems, which are names and places")
+	flag.StringVar(&opts.Output, "output", "./3-to-9-letter-words.txt", "file to write the filtered words to")
+	flag.StringVar(&manifestFile, "manifest", "", "manifest file to write (default <output>.manifest.json)")
+	flag.IntVar(&opts.MinLength, "min-length", 3, "minimum word length in letters")
+	flag.IntVar(&opts.MaxLength, "max-length", 9, "maximum word length in letters, 0 for no limit")
+	flag.StringVar(&opts.Include, "include", "", "only keep words matching this regex")
+	flag.StringVar(&opts.Exclude, "exclude", "", "drop words matching this regex")
+	flag.StringVar(&opts.Allowlist, "allowlist", "", "only keep words listed in this file")
+	flag.StringVar(&opts.Denylist, "denylist", "", "drop words listed in this file")
+	flag.BoolVar(&opts.Dedupe, "dedupe", false, "drop repeated words")
+	flag.BoolVar(&opts.Sort, "sort", false, "sort the output")
+	flag.Float64Var(&opts.MinFrequency, "min-frequency", 0, "only keep words with at least this frequency, either from a TSV frequency column or the number of times they appear")
+	flag.BoolVar(&opts.Normalise, "normalise", false, "lowercase, fold diacritics and drop non a-z words first")
+	flag.Parse()
+
+	if manifestFile == "" {
+		manifestFile = opts.Output + ".manifest.json"
+	}
+
+	words, result, err := buildDictionary(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := writeWords(opts.Output, words); err != nil {
+		log.Fatal(err)
+	}
+
+	if result.Affixes, err = optionalDigest(opts.Affixes); err != nil {
+		log.Fatal(err)
+	}
+	if result.Allowlist, err = optionalDigest(opts.Allowlist); err != nil {
+		log.Fatal(err)
+	}
+	if result.Denylist, err = optionalDigest(opts.Denylist); err != nil {
+		log.Fatal(err)
+	}
+	if result.Input, err = hashFile(opts.Input); err != nil {
+		log.Fatal(err)
+	}
+	if result.Output, err = hashFile(opts.Output); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := writeManifest(manifestFile, result); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Read %d words, wrote %d to %s\n", result.WordsRead, result.WordsWritten, opts.Output)
+	for _, f := range result.Filters {
+		fmt.Printf("  %s rejected %d\n", f.Filter, f.Rejected)
+	}
+}
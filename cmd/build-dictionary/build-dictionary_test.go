@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, name, contents string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestBuildDictionary(t *testing.T) {
+	opts := options{
+		Input:        writeTempFile(t, "words.txt", "zebra\nat\napple\nzebra\ncrisps\nbanana\napple\nzebra\nsquirrels\n"),
+		Denylist:     writeTempFile(t, "deny.txt", "crisps\n"),
+		MinLength:    3,
+		MaxLength:    6,
+		Exclude:      "^b",
+		Dedupe:       true,
+		Sort:         true,
+		MinFrequency: 2,
+	}
+
+	words, result, err := buildDictionary(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(words, ",") != "apple,zebra" {
+		t.Errorf("Words were incorrect, got: %v, want: %v.", words, []string{"apple", "zebra"})
+	}
+
+	want := map[string]int{"length": 2, "exclude": 1, "denylist": 1, "frequency": 0}
+	for _, f := range result.Filters {
+		if f.Rejected != want[f.Filter] {
+			t.Errorf("%s rejected count was incorrect, got: %d, want: %d.", f.Filter, f.Rejected, want[f.Filter])
+		}
+	}
+
+	if result.WordsRead != 9 || result.WordsWritten != 2 {
+		t.Errorf("Counts were incorrect, got: %d read %d written, want: 9 read 2 written.", result.WordsRead, result.WordsWritten)
+	}
+}
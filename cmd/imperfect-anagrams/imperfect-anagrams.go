@@ -1,103 +1,65 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"github.com/joeyciechanowicz/letter-combinations/pkg/rune-tree"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
 	"github.com/joeyciechanowicz/letter-combinations/pkg/stats"
 	"log"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
 type wordAnagramsPair struct {
 	word          string
 	anagramsCount int
-}
-
-func isWord1AnagramOfWord2(word1 *rune_tree.WordDetails, word2 *rune_tree.WordDetails) bool {
-	if len(word1.Word) > len(word2.Word) {
-		return false
-	}
-
-	// Iterate the main words runes, setting an index for the other word
-	i := -1
-	for j := 0; j < len(word1.SortedRuneCounts); j++ {
-		runeAndCount := word1.SortedRuneCounts[j]
-
-		// move the other words index along until we find a letter that matches
-		// returning false if we reach the end or the rune counts are incorrect
-		for {
-			i++
-			if i == len(word2.SortedRuneCounts) {
-				return false
-			}
-
-			if runeAndCount.Letter == word2.SortedRuneCounts[i].Letter {
-				if runeAndCount.Count <= word2.SortedRuneCounts[i].Count {
-					break
-				} else {
-					return false
-				}
-			}
-		}
-	}
-
-	return true
+	frequency     float64
 }
 
 /**
-Recursively searches the trie for any words that can be spelt using the given set of letter
-
-Works by taking the trie head and set of letters and trying to walk the trie using that set.
-However we iterate the letters each time to allow us to search and find words that are anagrams
-i.e. then (e,h,n,t) can spell hen (e,h,n) and net (e,n,t). By iterating AND recursing we check all branches of the trie
-that could contain anagrams.
+More anagrams wins, ties go to the more frequent word
  */
-func searchSet(letters []rune_tree.RuneCount, start int, head *rune_tree.Node, currentWord *rune_tree.WordDetails, anagramsCount *int) {
-	if len(head.Words) > 0 {
-		for i := 0; i < len(head.Words); i++ {
-			if isWord1AnagramOfWord2(head.Words[i], currentWord) {
-				*anagramsCount += 1
-			}
-		}
-	}
-
-	for i := start; i < len(letters); i++ {
-		if _, ok := head.Children[letters[i].Letter]; ok {
-			searchSet(letters, i+1, head.Children[letters[i].Letter], currentWord, anagramsCount)
-		}
+func (pair wordAnagramsPair) beats(other wordAnagramsPair) bool {
+	if pair.anagramsCount != other.anagramsCount {
+		return pair.anagramsCount > other.anagramsCount
 	}
+	return pair.frequency > other.frequency
 }
 
 /**
 Takes words off a channel and finds all the anagrams for that word
  */
-func findAnagrams(trie *rune_tree.Node, wordChan <-chan rune_tree.WordDetails, rateIncrements chan<- bool, maxAnagram chan<- wordAnagramsPair) {
-	var maxAnagramCount int
-	var maxWord string
+func findAnagrams(index trie.Index, wordChan <-chan trie.WordDetails, rateIncrements chan<- bool, maxAnagram chan<- wordAnagramsPair) {
+	var max wordAnagramsPair
 
 	for {
 		currentWord, ok := <-wordChan
 		if !ok {
-			maxAnagram <- wordAnagramsPair{maxWord, maxAnagramCount}
+			maxAnagram <- max
 			return
 		}
 
 		anagramsCount := 0
-		searchSet(currentWord.SortedRuneCounts, 0, trie, &currentWord, &anagramsCount)
-
-		if anagramsCount > maxAnagramCount {
-			maxWord = currentWord.Word
-			maxAnagramCount = anagramsCount
+		trie.EachSubAnagram(index, trie.Query{Letters: currentWord.SortedLetterCounts}, func(*trie.WordDetails) bool {
+			anagramsCount++
+			return true
+		})
+
+		pair := wordAnagramsPair{currentWord.Word, anagramsCount, currentWord.Frequency}
+		if pair.beats(max) {
+			max = pair
 		}
 
 		rateIncrements <- true
 	}
 }
 
-func walkTrie(node *rune_tree.Node, wordChan chan<- rune_tree.WordDetails) {
+func walkTrie(node *trie.Node, wordChan chan<- trie.WordDetails) {
 	if len(node.Children) == 0 {
 		for _, word := range node.Words {
 			wordChan <- *word
@@ -110,34 +72,37 @@ func walkTrie(node *rune_tree.Node, wordChan chan<- rune_tree.WordDetails) {
 }
 
 
-func findWordWithMostAnagrams(trie rune_tree.Node, words []rune_tree.WordDetails) {
+func findWordWithMostAnagrams(root trie.Node, words []trie.WordDetails) {
 	const numCpus = 8
 
+	flat, err := trie.NewFlatTrie(&root, words)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	finished := make(chan bool)
 	maxAnagrams := make(chan wordAnagramsPair)
-	wordChan := make(chan rune_tree.WordDetails, numCpus)
+	wordChan := make(chan trie.WordDetails, numCpus)
 	statUpdates := make(chan bool, numCpus)
 
 	go stats.PrintRate(finished, statUpdates)
 
 	for i := 0; i < numCpus; i++ {
-		go findAnagrams(&trie, wordChan, statUpdates, maxAnagrams)
+		go findAnagrams(flat, wordChan, statUpdates, maxAnagrams)
 	}
 
 	go func() {
-		walkTrie(&trie, wordChan)
+		walkTrie(&root, wordChan)
 
 		close(wordChan)
 	}()
 
-	var maxAnagramCount int
-	var maxWord string
+	var max wordAnagramsPair
 	for i := 0; i < numCpus; i++ {
 		select {
 			case pair := <- maxAnagrams:
-				if pair.anagramsCount > maxAnagramCount {
-					maxWord = pair.word
-					maxAnagramCount = pair.anagramsCount
+				if pair.beats(max) {
+					max = pair
 				}
 		}
 	}
@@ -148,7 +113,43 @@ func findWordWithMostAnagrams(trie rune_tree.Node, words []rune_tree.WordDetails
 	close(maxAnagrams)
 	close(statUpdates)
 
-	fmt.Printf("\n\nLongest word: %s with %d imperfect-anagrams\n", maxWord, maxAnagramCount)
+	fmt.Printf("\n\nLongest word: %s with %d imperfect-anagrams\n", max.word, max.anagramsCount)
+}
+
+/**
+Prints the top largest perfect-anagram families for each word length, longest words first
+ */
+func printLargestFamilies(root trie.Node, words []trie.WordDetails, top int) {
+	flat, err := trie.NewFlatTrie(&root, words)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	byLength := make(map[int][]trie.Family)
+	for _, family := range trie.Families(flat, trie.English, 2) {
+		length := utf8.RuneCountInString(family.Key)
+		if len(byLength[length]) < top {
+			byLength[length] = append(byLength[length], family)
+		}
+	}
+
+	var lengths []int
+	for length := range byLength {
+		lengths = append(lengths, length)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+
+	for _, length := range lengths {
+		fmt.Printf("%d letters\n", length)
+
+		for _, family := range byLength[length] {
+			var words []string
+			for _, word := range family.Words {
+				words = append(words, word.Word)
+			}
+			fmt.Printf("  %s (%d): %s\n", family.Key, len(family.Words), strings.Join(words, ", "))
+		}
+	}
 }
 
 var cpuprofile = "cpu.prof"
@@ -157,12 +158,52 @@ var cpuprofile = "cpu.prof"
 //var memprofile = "mem.prof"
 var memprofile = ""
 
-func main() {
-	//var trie, words = dictionary_tree.CreateRuneDictionaryTree("./words_alpha.txt")
-	var trie, words = rune_tree.CreateRuneDictionaryTree("./words_no-names-or-places.txt")
-	//var trie, words = dictionary_tree.CreateRuneDictionaryTree("./first_2000_words.txt")
+func loadDictionary(filename string, dictionaryFlags trie.DictionaryFlags) (trie.Node, []trie.WordDetails) {
+	opts, err := dictionaryFlags.Options(context.Background(), trie.English)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	root, words, err := trie.OpenDictionary(context.Background(), filename, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return root, words
+}
 
+func main() {
+	dictionaryFlags := trie.AddDictionaryFlags(flag.CommandLine)
+	families := flag.Int("families", 0, "list the largest N perfect-anagram families of each length instead of searching")
+	anagramsOf := flag.String("anagrams", "", "list the perfect anagrams of this word instead of searching")
 	flag.Parse()
+
+	//var root, words = trie.Create("./words_alpha.txt", trie.English)
+	var root, words = loadDictionary("./words_no-names-or-places.txt", dictionaryFlags)
+	//var root, words = trie.Create("./first_2000_words.txt", trie.English)
+
+	if *anagramsOf != "" {
+		flat, err := trie.NewFlatTrie(&root, words)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		anagrams, err := trie.Anagrams(flat, trie.English, *anagramsOf)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, word := range anagrams {
+			fmt.Println(word.Word)
+		}
+		return
+	}
+
+	if *families > 0 {
+		printLargestFamilies(root, words, *families)
+		return
+	}
+
 	if cpuprofile != "" {
 		f, err := os.Create(cpuprofile)
 		if err != nil {
@@ -175,7 +216,7 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	findWordWithMostAnagrams(trie, words)
+	findWordWithMostAnagrams(root, words)
 
 	if memprofile != "" {
 		f, err := os.Create(memprofile)
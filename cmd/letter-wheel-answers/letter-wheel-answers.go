@@ -1,108 +1,135 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"flag"
 	"fmt"
-	"io"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/render"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
 	"log"
-	"os"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 //const filename = "./first_1000-3-to-9-letter-words.txt"
 const filename = "./3-to-9-letter-words.txt"
 
-func shouldWordBeIncluded(word string, mainLetter string, letterCounts map[string]int) bool {
-	lettersSeen := make(map[string]int)
+const blank = "?"
 
-	for _, letter := range strings.Split(word, "") {
-		lettersSeen[letter] += 1
+// Same as letter-wheel's largest wheel
+const MAX_WHEEL_SIZE = 16
 
-		if lettersSeen[letter] > letterCounts[letter] {
-			return false
+/**
+Builds the query for a wheel, the centre letter has to be used unless it's a blank in which case it can stand in for
+any letter of the word
+ */
+func buildQuery(mainLetter string, letterCounts map[string]int) (trie.Query, error) {
+	var query trie.Query
+	var letters string
+
+	for letter, count := range letterCounts {
+		if letter == blank {
+			query.Blanks = count
+			continue
 		}
+		letters += strings.Repeat(letter, count)
 	}
 
-	return lettersSeen[mainLetter] >= 1
+	details, err := trie.NewWordDetails(trie.English, letters)
+	if err != nil {
+		return query, err
+	}
+	query.Letters = details.SortedLetterCounts
+
+	if mainLetter != blank {
+		query.Required = []trie.LetterCount{{Letter: trie.English.Index([]rune(mainLetter)[0]), Count: 1}}
+	}
+
+	return query, nil
 }
 
-func parseArgs() (string, map[string]int) {
-	args := os.Args[1:]
+/**
+The word followed by what any blanks stood for, i.e. hello (?=h)
+ */
+func describe(match trie.Match) string {
+	if len(match.Blanks) == 0 {
+		return match.Word.Word
+	}
 
-	if len (args) != 9 {
-		panic("Incorrect number of arguments. Expected 9 letters")
+	var filled []string
+	for _, letterCount := range match.Blanks {
+		for i := 0; i < int(letterCount.Count); i++ {
+			filled = append(filled, string(trie.English.Letter(letterCount.Letter)))
+		}
 	}
 
-	letterCounts := make(map[string]int)
-	for _, letter := range args {
-		letterCounts[letter] += 1
+	return fmt.Sprintf("%s (%s=%s)", match.Word.Word, blank, strings.Join(filled, ","))
+}
+
+func loadDictionary(filename string, dictionaryFlags trie.DictionaryFlags) (trie.Node, []trie.WordDetails) {
+	opts, err := dictionaryFlags.Options(context.Background(), trie.English)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	return args[0], letterCounts
+	root, words, err := trie.OpenDictionary(context.Background(), filename, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return root, words
 }
 
-func printOutput(mainLetter string, letterCounts map[string]int, words []string, time time.Duration) {
-	var letters []string
+/**
+The centre letter, the letters on the ring in the order given and how many of each letter the wheel has
+ */
+func parseArgs() (string, []string, map[string]int, error) {
+	args := flag.Args()
 
-	for letter, count := range letterCounts {
-		if letter == mainLetter {
-			if count > 1 {
-				for i := 0; i < count - 1; i++ {
-					letters = append(letters, letter)
-				}
-			}
-			continue
-		}
+	if len(args) < render.MinWheelSize || len(args) > MAX_WHEEL_SIZE {
+		return "", nil, nil, fmt.Errorf("expected %d to %d letters, the centre first and %s for a blank, got %d", render.MinWheelSize, MAX_WHEEL_SIZE, blank, len(args))
+	}
 
-		for i := 0; i < count; i++ {
-			letters = append(letters, letter)
-		}
+	letterCounts := make(map[string]int)
+	for _, letter := range args {
+		letterCounts[letter] += 1
 	}
 
-	fmt.Printf("┏━━━━━━━━━━━┓\n")
-	fmt.Printf("┃ %s   %s   %s ┃\n", letters[0], letters[1], letters[2])
-	fmt.Printf("┃   ┏━━━┓   ┃\n")
-	fmt.Printf("┃ %s ┃ %s ┃ %s ┃  Found %d words in %dms\n", letters[3], mainLetter, letters[4], len(words), time.Nanoseconds() / 1e6)
-	fmt.Printf("┃   ┗━━━┛   ┃\n")
-	fmt.Printf("┃ %s   %s   %s ┃\n", letters[5], letters[6], letters[7])
-	fmt.Printf("┗━━━━━━━━━━━┛\n")
-
-	nineLetterString := "Nine letter words: "
-	nineLetterCount := 0
-	for _, word := range words {
-		if len(word) == 9 {
-			if nineLetterCount > 0 {
-				nineLetterString += ", "
-			}
-			nineLetterString += word
-			nineLetterCount++
+	return args[0], args[1:], letterCounts, nil
+}
+
+/**
+The words with length letters, comma separated
+ */
+func wordsOfLength(matches []trie.Match, length int) string {
+	var words []string
+	for _, match := range matches {
+		if utf8.RuneCountInString(match.Word.Word) == length {
+			words = append(words, describe(match))
 		}
 	}
-	if nineLetterCount > 0 {
-		fmt.Println(nineLetterString)
-	}
+	return strings.Join(words, ", ")
+}
+
+func printOutput(mainLetter string, outer []string, matches []trie.Match, time time.Duration) {
+	note := fmt.Sprintf("Found %d words in %dms", len(matches), time.Nanoseconds() / 1e6)
+	fmt.Print(render.Wheel(mainLetter, outer, note))
 
-	eightLetterString := "Eight letter words: "
-	eightLetterCount := 0
-	for _, word := range words {
-		if len(word) == 8 {
-			if eightLetterCount > 0 {
-				eightLetterString += ", "
-			}
-			eightLetterString += word
-			eightLetterCount++
+	// The longest words are the ones worth calling out, using every letter or all but one
+	size := len(outer) + 1
+	for _, length := range []int{size, size - 1} {
+		if words := wordsOfLength(matches, length); words != "" {
+			fmt.Printf("%d letter words: %s\n", length, words)
 		}
 	}
-	if eightLetterCount > 0 {
-		fmt.Println(eightLetterString)
-	}
 
 	fmt.Print("Words: ")
 
-	for i, word := range words {
-		fmt.Print(word)
-		if i != len(words) - 1 {
+	for i, match := range matches {
+		fmt.Print(describe(match))
+		if i != len(matches) - 1 {
 			fmt.Print(", ")
 		}
 	}
@@ -111,35 +138,37 @@ func printOutput(mainLetter string, letterCounts map[string]int, words []string,
 }
 
 func main() {
-	start := time.Now()
-	var words []string
-	mainLetter, letterCounts := parseArgs()
+	dictionaryFlags := trie.AddDictionaryFlags(flag.CommandLine)
+	rank := flag.Bool("rank", false, "list the most frequent words first")
+	flag.Parse()
 
-	fileHandle, err := os.Open(filename)
+	start := time.Now()
+	mainLetter, outer, letterCounts, err := parseArgs()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer closeIO(fileHandle)
 
-	scanner := bufio.NewScanner(fileHandle)
-	for scanner.Scan() {
-		word := scanner.Text()
-
-		if shouldWordBeIncluded(word, mainLetter, letterCounts) {
-			words = append(words, word)
-		}
-	}
-
-	printOutput(mainLetter, letterCounts, words, time.Since(start))
-
-	if err := scanner.Err(); err != nil {
+	query, err := buildQuery(mainLetter, letterCounts)
+	if err != nil {
 		log.Fatal(err)
 	}
-}
 
-func closeIO(c io.Closer) {
-	err := c.Close()
+	root, words := loadDictionary(filename, dictionaryFlags)
+	flat, err := trie.NewFlatTrie(&root, words)
 	if err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+
+	matches := trie.Matches(flat, query)
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Word.Word < matches[j].Word.Word
+	})
+	if *rank {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Word.Frequency > matches[j].Word.Frequency
+		})
+	}
+
+	printOutput(mainLetter, outer, matches, time.Since(start))
+}
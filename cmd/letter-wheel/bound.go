@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+/**
+The score a wheel has to reach to have a chance of being kept, shared between the workers. Once any worker has kept
+top wheels, a wheel scoring less than the worst of them can't be in the overall top either
+ */
+type scoreFloor struct {
+	value atomic.Int64
+}
+
+func (floor *scoreFloor) get() int {
+	return int(floor.value.Load())
+}
+
+func (floor *scoreFloor) raise(score int) {
+	for {
+		current := floor.value.Load()
+		if int64(score) <= current || floor.value.CompareAndSwap(current, int64(score)) {
+			return
+		}
+	}
+}
+
+/**
+An upper bound on the score of every wheel whose outer ring starts with fixed, has free more letters no earlier
+than minLetter, and any centre. Words that fit without the centre count towards every wheel, the rest only
+towards wheels whose centre is the letter they need, so the bound is those that always fit plus the most any one
+centre adds. One traversal bounds every ring and centre under the prefix
+ */
+func wheelBound(index trie.Index, objective objective, fixed [26]byte, free, minLetter int) int {
+	var always int
+	var byCentre [26]int
+
+	boundWords(index, objective, index.Root(), 0, &fixed, free, minLetter, 0, 0, &always, &byCentre)
+
+	best := 0
+	for _, count := range byCentre {
+		best = max(best, count)
+	}
+
+	return always + best
+}
+
+/**
+extra is the fewest letters the words under head need beyond fixed, and early how many of those come before
+minLetter. Those can only be the centre, so at most one is allowed
+ */
+func boundWords(index trie.Index, objective objective, head trie.Cursor, start int, fixed *[26]byte, free, minLetter, extra, early int, always *int, byCentre *[26]int) {
+	dictionary := index.Dictionary()
+	for _, i := range index.Words(head) {
+		word := &dictionary[i]
+		wordExtra, wordEarly, earlyLetter := 0, 0, 0
+
+		for _, letterCount := range word.SortedLetterCounts {
+			if letterCount.Count > fixed[letterCount.Letter] {
+				needed := int(letterCount.Count - fixed[letterCount.Letter])
+				wordExtra += needed
+				if letterCount.Letter < minLetter {
+					wordEarly += needed
+					earlyLetter = letterCount.Letter
+				}
+			}
+		}
+
+		switch {
+		case wordEarly > 1 || wordExtra > free+1:
+		case wordEarly == 1:
+			byCentre[earlyLetter] += objective.score(word)
+		case wordExtra <= free:
+			*always += objective.score(word)
+		default:
+			// One of the letters it needs has to be the centre
+			score := objective.score(word)
+			for _, letterCount := range word.SortedLetterCounts {
+				if letterCount.Count > fixed[letterCount.Letter] {
+					byCentre[letterCount.Letter] += score
+				}
+			}
+		}
+	}
+
+	for letter := start; letter < trie.English.Size(); letter++ {
+		childExtra, childEarly := extra, early
+		if fixed[letter] == 0 {
+			childExtra++
+			if letter < minLetter {
+				childEarly++
+			}
+		}
+
+		if childEarly > 1 || childExtra > free+1 {
+			continue
+		}
+
+		if child, ok := index.Child(head, letter); ok {
+			boundWords(index, objective, child, letter+1, fixed, free, minLetter, childExtra, childEarly, always, byCentre)
+		}
+	}
+}
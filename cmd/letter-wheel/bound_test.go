@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+func TestWheelBoundIsAnUpperBound(t *testing.T) {
+	layout, _ := newWheelLayout(5, true)
+
+	checked := 0
+	combinationRepetition(func(ring []int) {
+		if checked%7 != 0 {
+			checked++
+			return
+		}
+		checked++
+
+		for prefix := 1; prefix <= len(ring); prefix++ {
+			bound := wheelBound(flat, wordCount{}, countLetters(ring[:prefix]), len(ring)-prefix, ring[prefix-1])
+
+			for centre := 0; centre < 26; centre++ {
+				letterCounts := countLetters(append([]int{centre}, ring...))
+				var compressed []trie.LetterCount
+				for letter, count := range letterCounts {
+					if count > 0 {
+						compressed = append(compressed, trie.LetterCount{Letter: letter, Count: count})
+					}
+				}
+
+				count := 0
+				findWordsForWheel(flat, flat.Root(), 0, newWheel(centre, compressed), &count)
+				if count > bound {
+					t.Fatalf("Bound of %v for prefix %d was incorrect, got: %d, want at least: %d.", ring, prefix, bound, count)
+				}
+			}
+		}
+	}, nil, layout, nil, shard{})
+}
+
+func TestPrunedSearchMatches(t *testing.T) {
+	for _, size := range []int{4, 5} {
+		for _, top := range []int{1, 5} {
+			layout, _ := newWheelLayout(size, true)
+			opts := searchOptions{layout: layout, workers: 3, top: top, dictionary: "test", objective: wordCount{}}
+
+			exhaustive := findBestLetterWheels(root, words, opts)
+			opts.prune = true
+			pruned := findBestLetterWheels(root, words, opts)
+
+			if len(pruned) != len(exhaustive) {
+				t.Fatalf("Length was incorrect, got: %d, want: %d.", len(pruned), len(exhaustive))
+			}
+			for i := range pruned {
+				if pruned[i].wheel.key() != exhaustive[i].wheel.key() || pruned[i].score != exhaustive[i].score {
+					t.Errorf("Size %d top %d rank %d was incorrect, got: %s %d, want: %s %d.", size, top, i+1, pruned[i].wheel.key(), pruned[i].score, exhaustive[i].wheel.key(), exhaustive[i].score)
+				}
+			}
+		}
+	}
+}
+
+func TestPrunedShardsMerge(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, workers: 2, top: 3, dictionary: "test", objective: wordCount{}}
+
+	exhaustive := findBestLetterWheels(root, words, opts)
+
+	opts.prune = true
+	results := runShards(t, opts, 3)
+
+	merged, searched, pruned, err := mergeShards(results, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if searched+pruned != layout.totalRings() {
+		t.Errorf("Searched and pruned were incorrect, got: %d + %d, want: %d.", searched, pruned, layout.totalRings())
+	}
+	for i := range merged {
+		if merged[i].wheel.key() != exhaustive[i].wheel.key() || merged[i].score != exhaustive[i].score {
+			t.Errorf("Rank %d was incorrect, got: %s %d, want: %s %d.", i+1, merged[i].wheel.key(), merged[i].score, exhaustive[i].wheel.key(), exhaustive[i].score)
+		}
+	}
+}
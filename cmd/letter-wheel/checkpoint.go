@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+const checkpointVersion = 2
+
+/**
+Progress of a search. Every ring up to and including Position, in the order combinationRepetition produces them,
+has been searched, or Pruned because it couldn't beat them, and Ranked holds the best wheels among them. A complete checkpoint of a shard is its result,
+which merge combines with the other shards
+ */
+type checkpoint struct {
+	Version    int             `json:"version"`
+	Dictionary string          `json:"dictionary"`
+	Size       int             `json:"size"`
+	Letters    int             `json:"letters"`
+	Top        int             `json:"top"`
+	Objective  string          `json:"objective"`
+	Shard      string          `json:"shard,omitempty"`
+	Position   string          `json:"position,omitempty"`
+	Searched   int             `json:"searched"`
+	Pruned     int             `json:"pruned,omitempty"`
+	Complete   bool            `json:"complete"`
+	Ranked     []exportedWheel `json:"ranked"`
+}
+
+func newCheckpoint(opts searchOptions) *checkpoint {
+	return &checkpoint{
+		Version:    checkpointVersion,
+		Dictionary: opts.dictionary,
+		Size:       opts.layout.size,
+		Letters:    opts.layout.letters,
+		Top:        opts.top,
+		Objective:  opts.objective.String(),
+		Shard:      opts.shard.String(),
+	}
+}
+
+/**
+Whether a search set up like other can carry on from c
+ */
+func (c *checkpoint) compatible(other *checkpoint) error {
+	switch {
+	case c.Version != other.Version:
+		return fmt.Errorf("checkpoint version %d can't be resumed, expected %d", c.Version, other.Version)
+	case c.Dictionary != other.Dictionary:
+		return fmt.Errorf("checkpoint is for a different dictionary")
+	case c.Size != other.Size || c.Letters != other.Letters:
+		return fmt.Errorf("checkpoint is for %d letter wheels from %d letters, not %d from %d", c.Size, c.Letters, other.Size, other.Letters)
+	case c.Top != other.Top:
+		return fmt.Errorf("checkpoint kept the top %d wheels, not %d", c.Top, other.Top)
+	case c.Objective != other.Objective:
+		return fmt.Errorf("checkpoint scored wheels on %s, not %s", c.Objective, other.Objective)
+	case c.Shard != other.Shard:
+		return fmt.Errorf("checkpoint is for shard %q, not %q", c.Shard, other.Shard)
+	}
+	return nil
+}
+
+func (c *checkpoint) setPosition(ring []int) {
+	var position []rune
+	for _, letter := range ring {
+		position = append(position, trie.English.Letter(letter))
+	}
+	c.Position = string(position)
+}
+
+/**
+The last ring searched, nil if none have been
+ */
+func (c *checkpoint) position() ([]int, error) {
+	if c.Position == "" {
+		return nil, nil
+	}
+
+	var ring []int
+	for _, letter := range c.Position {
+		index := trie.English.Index(letter)
+		if index < 0 {
+			return nil, fmt.Errorf("checkpoint position %q contains %q", c.Position, letter)
+		}
+		ring = append(ring, index)
+	}
+
+	if len(ring) != c.Size-1 {
+		return nil, fmt.Errorf("checkpoint position %q should have %d letters", c.Position, c.Size-1)
+	}
+
+	return ring, nil
+}
+
+/**
+Turns exported wheels back into results
+ */
+func importWheels(wheels []exportedWheel) ([]scoredWheel, error) {
+	var results []scoredWheel
+
+	for _, wheel := range wheels {
+		details, err := trie.NewWordDetails(trie.English, wheel.Letters)
+		if err != nil {
+			return nil, err
+		}
+
+		centre := []rune(wheel.Centre)
+		if len(centre) != 1 || trie.English.Index(centre[0]) < 0 {
+			return nil, fmt.Errorf("wheel %s has an invalid centre %q", wheel.Letters, wheel.Centre)
+		}
+
+		results = append(results, scoredWheel{newWheel(trie.English.Index(centre[0]), details.SortedLetterCounts), wheel.Score})
+	}
+
+	return results, nil
+}
+
+/**
+Writes to a temporary file first so an interruption part way through doesn't lose the previous checkpoint
+ */
+func saveCheckpoint(filename string, c *checkpoint) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), filename)
+}
+
+func loadCheckpoint(filename string) (*checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, workers: 3, top: 5, dictionary: "test", objective: wordCount{}}
+
+	uninterrupted := findBestLetterWheels(root, words, opts)
+
+	// What a search interrupted after its first 200 rings would have saved
+	var rings [][]int
+	combinationRepetition(func(ring []int) {
+		rings = append(rings, ring)
+	}, nil, layout, nil, shard{})
+
+	best := newTopWheels(opts.top)
+	wheelChan := make(chan []int, 200)
+	stats := make(chan bool, 200*26)
+	var processed sync.WaitGroup
+
+	for _, ring := range rings[:200] {
+		processed.Add(1)
+		wheelChan <- ring
+	}
+	close(wheelChan)
+	findWords(flat, wordCount{}, wheelChan, stats, best, &processed, nil)
+
+	interrupted := newCheckpoint(opts)
+	interrupted.setPosition(rings[199])
+	interrupted.Searched = 200
+	interrupted.Ranked = exportedWheels(best.ranked())
+
+	// Pruning skips rings but shouldn't change the result, or lose track of how many were covered
+	for _, prune := range []bool{false, true} {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		if err := saveCheckpoint(filename, interrupted); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := loadCheckpoint(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts.resume = loaded
+		opts.checkpointFile = filename
+		opts.prune = prune
+		resumed := findBestLetterWheels(root, words, opts)
+
+		if len(resumed) != len(uninterrupted) {
+			t.Fatalf("Length was incorrect, got: %d, want: %d.", len(resumed), len(uninterrupted))
+		}
+		for i := range resumed {
+			if resumed[i].wheel.key() != uninterrupted[i].wheel.key() || resumed[i].score != uninterrupted[i].score {
+				t.Errorf("Rank %d was incorrect, got: %s %d, want: %s %d.", i+1, resumed[i].wheel.key(), resumed[i].score, uninterrupted[i].wheel.key(), uninterrupted[i].score)
+			}
+		}
+
+		final, err := loadCheckpoint(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !final.Complete || final.Searched+final.Pruned != len(rings) {
+			t.Errorf("Final checkpoint was incorrect, got: %t %d, want: %t %d.", final.Complete, final.Searched+final.Pruned, true, len(rings))
+		}
+	}
+}
+
+func TestIncompatibleCheckpoint(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, top: 5, dictionary: "test", objective: wordCount{}}
+
+	other := newCheckpoint(opts)
+	other.Dictionary = "other"
+	if err := other.compatible(newCheckpoint(opts)); err == nil {
+		t.Errorf("Expected an error for a different dictionary")
+	}
+
+	other = newCheckpoint(opts)
+	other.Top = 10
+	if err := other.compatible(newCheckpoint(opts)); err == nil {
+		t.Errorf("Expected an error for a different top")
+	}
+}
@@ -1,83 +1,170 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
-	"github.com/joeyciechanowicz/letter-combinations/pkg/int-tree"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/render"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
 	"github.com/joeyciechanowicz/letter-combinations/pkg/stats"
 	"log"
 	"os"
+	"runtime"
 	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-const WHEEL_SIZE = 9
-const TOTAL_WHEELS = 52451256
+const DEFAULT_WHEEL_SIZE = 9
+const MIN_WHEEL_SIZE = render.MinWheelSize
+const MAX_WHEEL_SIZE = 16
 
-type wordCountForWheel struct {
+// Prefixes are only bounded when this many letters of their ring are left to choose. Bounds with more free letters are
+// rarely low enough to prune anything and cost far more to count than the rings they cover
+const MAX_PRUNE_FREE_LETTERS = 1
+
+/**
+The shape of the wheels being searched, size letters including the centre. The outer ring is chosen from the first
+letters of the alphabet, fewer than 26 in test mode, and the centre from all 26
+ */
+type wheelLayout struct {
+	size    int
+	letters int
+}
+
+func newWheelLayout(size int, testMode bool) (wheelLayout, error) {
+	if size < MIN_WHEEL_SIZE || size > MAX_WHEEL_SIZE {
+		return wheelLayout{}, fmt.Errorf("wheel size must be between %d and %d, got %d", MIN_WHEEL_SIZE, MAX_WHEEL_SIZE, size)
+	}
+
+	if testMode {
+		return wheelLayout{size, 15}, nil
+	}
+	return wheelLayout{size, 26}, nil
+}
+
+func (layout wheelLayout) outer() int {
+	return layout.size - 1
+}
+
+/**
+n choose k
+ */
+func binomial(n, k int) int {
+	result := 1
+	for i := 1; i <= k; i++ {
+		result = result * (n - k + i) / i
+	}
+	return result
+}
+
+/**
+Every outer ring is a combination with repetition of the layout's letters
+ */
+func (layout wheelLayout) totalRings() int {
+	return binomial(layout.letters+layout.outer()-1, layout.outer())
+}
+
+/**
+Each ring is tried with 26 centre letters
+ */
+func (layout wheelLayout) totalWheels() int {
+	return layout.totalRings() * 26
+}
+
+type scoredWheel struct {
 	wheel Wheel
-	wordsCount int
+	score int
 }
 
 type Wheel struct {
 	MainLetter   int
-	LetterCounts []int_tree.LetterCount
+	LetterCounts []trie.LetterCount
+
+	// Same counts packed for trie.PackedCounts.Contains, with any count above trie.MaxPackedCount capped
+	// which is safe as only words with smaller counts can be packed
+	Counts trie.PackedCounts
 }
 
-func canWordBeSpeltFromWheel(word []int_tree.LetterCount, wheel Wheel) bool {
-	seenMainLetter := false
+func newWheel(mainLetter int, letterCounts []trie.LetterCount) Wheel {
+	var counts trie.PackedCounts
 
-	// Iterate the main words runes, setting an index for the other word
-	i := -1
+	for _, letterCount := range letterCounts {
+		count := letterCount.Count
+		if count > trie.MaxPackedCount {
+			count = trie.MaxPackedCount
+		}
+		counts = counts.Add(letterCount.Letter, count)
+	}
 
-	for j := 0; j < len(word); j++ {
-		letterAndCount := word[j]
+	return Wheel{MainLetter: mainLetter, LetterCounts: letterCounts, Counts: counts}
+}
 
-		if letterAndCount.Letter == wheel.MainLetter {
-			seenMainLetter = true
+/**
+The wheel with letterCounts of each letter, the centre included
+ */
+func wheelFromCounts(mainLetter int, letterCounts [26]byte) Wheel {
+	var compressedLetterCounts []trie.LetterCount
+
+	for letter, count := range letterCounts {
+		if count > 0 {
+			compressedLetterCounts = append(compressedLetterCounts, trie.LetterCount{
+				Letter: letter,
+				Count:  count,
+			})
 		}
+	}
 
-		// move the other words index along until we find a letter that matches
-		// returning false if we reach the end or the rune counts are incorrect
-		for {
-			i++
-			if i == len(wheel.LetterCounts) {
-				return false
-			}
+	return newWheel(mainLetter, compressedLetterCounts)
+}
 
-			if letterAndCount.Letter == wheel.LetterCounts[i].Letter {
-				if letterAndCount.Count <= wheel.LetterCounts[i].Count {
-					break
-				} else {
-					return false
-				}
-			}
+func canWordBeSpeltFromWheel(word *trie.WordDetails, wheel Wheel) bool {
+	if word.Packed {
+		return word.LetterMask&(1<<uint(wheel.MainLetter)) != 0 && wheel.Counts.Contains(word.PackedCounts)
+	}
+
+	seenMainLetter := false
+	for _, letterCount := range word.SortedLetterCounts {
+		if letterCount.Letter == wheel.MainLetter {
+			seenMainLetter = true
 		}
 	}
 
-	return seenMainLetter
+	return seenMainLetter && trie.IsSubset(word.SortedLetterCounts, wheel.LetterCounts)
 }
 
-func findWordsForWheel(head *int_tree.Node, start int, currentWheel Wheel, wheelCount *int) {
-	if len(head.Words) > 0 {
-		for i := 0; i < len(head.Words); i++ {
-			if canWordBeSpeltFromWheel(head.Words[i].SortedLetterCounts, currentWheel) {
-				*wheelCount++
-			}
+func findWordsForWheel(index trie.Index, head trie.Cursor, start int, currentWheel Wheel, wheelCount *int) {
+	scoreWordsForWheel(index, head, start, currentWheel, wordCount{}, wheelCount)
+}
+
+/**
+Adds what every word the wheel spells scores under objective to wheelScore
+ */
+func scoreWordsForWheel(index trie.Index, head trie.Cursor, start int, currentWheel Wheel, objective objective, wheelScore *int) {
+	dictionary := index.Dictionary()
+	for _, i := range index.Words(head) {
+		if canWordBeSpeltFromWheel(&dictionary[i], currentWheel) {
+			*wheelScore += objective.score(&dictionary[i])
 		}
 	}
 
 	for i := start; i < len(currentWheel.LetterCounts); i++ {
-		if _, ok := head.Children[currentWheel.LetterCounts[i].Letter]; ok {
-			findWordsForWheel(head.Children[currentWheel.LetterCounts[i].Letter], i+1, currentWheel, wheelCount)
+		if child, ok := index.Child(head, currentWheel.LetterCounts[i].Letter); ok {
+			scoreWordsForWheel(index, child, i+1, currentWheel, objective, wheelScore)
 		}
 	}
 }
 
-func countLetters(wheel [WHEEL_SIZE - 1]int) [26]byte {
+func countLetters(wheel []int) [26]byte {
 	var letterCounts [26]byte
 
 	// Abuse that we know exactly the range of our data (26 letters in the alphabet)
-	for i := 0; i < WHEEL_SIZE-1; i++ {
+	for i := 0; i < len(wheel); i++ {
 		letterCounts[wheel[i]]++
 	}
 
@@ -85,147 +172,456 @@ func countLetters(wheel [WHEEL_SIZE - 1]int) [26]byte {
 }
 
 /**
-Takes the 8 surrounding wheel runes off a channel, iterates the centre 26 letters and finds the word-count for each wheel
+Scores all 26 wheels made from a ring and each centre letter in one traversal. A word spelt from the ring alone
+counts towards every centre it contains, one needing a single letter more only towards that letter
  */
-func findWords(trie *int_tree.Node, wheelChan <-chan [WHEEL_SIZE - 1]int, stats chan<- bool, maxWheelChan chan<- wordCountForWheel) {
-	var maxCount int
-	var maxWheel Wheel
+type centreCount struct {
+	index     trie.Index
+	objective objective
+	ring      [26]byte
+
+	// Counting words is by far the most common objective, so it skips calling it
+	countWords bool
+
+	// Only usable when the ring plus a centre fits, see trie.PackCounts
+	packed  trie.PackedCounts
+	canPack bool
+
+	counts [26]int
+}
+
+func countWordsForCentres(index trie.Index, objective objective, ring [26]byte) [26]int {
+	count := &centreCount{index: index, objective: objective, ring: ring, canPack: true}
+	_, count.countWords = objective.(wordCount)
+
+	for letter, letterCount := range ring {
+		if letterCount >= trie.MaxPackedCount {
+			count.canPack = false
+		}
+		count.packed = count.packed.Add(letter, letterCount)
+	}
+
+	count.walk(index.Root(), 0, -1)
+	return count.counts
+}
+
+func (count *centreCount) score(word *trie.WordDetails) int {
+	if count.countWords {
+		return 1
+	}
+	return count.objective.score(word)
+}
+
+/**
+extraLetter is the letter the path to head took that isn't in the ring, -1 if there isn't one
+ */
+func (count *centreCount) walk(head trie.Cursor, start int, extraLetter int) {
+	dictionary := count.index.Dictionary()
+	for _, i := range count.index.Words(head) {
+		word := &dictionary[i]
+		packed := count.canPack && word.Packed
+
+		if packed && extraLetter >= 0 {
+			if count.packed.Add(extraLetter, 1).Contains(word.PackedCounts) {
+				count.counts[extraLetter] += count.score(word)
+			}
+			continue
+		}
+
+		if packed && count.packed.Contains(word.PackedCounts) {
+			score := count.score(word)
+			for _, letterCount := range word.SortedLetterCounts {
+				count.counts[letterCount.Letter] += score
+			}
+			continue
+		}
+
+		// Still fits if only one letter is short, and only by one
+		needed, neededLetter := 0, 0
+		for _, letterCount := range word.SortedLetterCounts {
+			if letterCount.Count > count.ring[letterCount.Letter] {
+				needed += int(letterCount.Count - count.ring[letterCount.Letter])
+				neededLetter = letterCount.Letter
+			}
+		}
 
+		switch needed {
+		case 0:
+			score := count.score(word)
+			for _, letterCount := range word.SortedLetterCounts {
+				count.counts[letterCount.Letter] += score
+			}
+		case 1:
+			count.counts[neededLetter] += count.score(word)
+		}
+	}
+
+	for letter := start; letter < trie.English.Size(); letter++ {
+		childExtra := extraLetter
+		if count.ring[letter] == 0 {
+			if extraLetter >= 0 {
+				continue
+			}
+			childExtra = letter
+		}
+
+		if child, ok := count.index.Child(head, letter); ok {
+			count.walk(child, letter+1, childExtra)
+		}
+	}
+}
+
+/**
+Takes the surrounding wheel letters off a channel, scores all 26 centre letters with objective and offers each wheel
+with its score to best. Marks each ring done on processed once all its centres have been tried. With a floor,
+best's worst kept score is shared through it so the generator can prune rings
+ */
+func findWords(index trie.Index, objective objective, wheelChan <-chan []int, stats chan<- bool, best *topWheels, processed *sync.WaitGroup, floor *scoreFloor) {
 	for {
 		currentWheel, ok := <-wheelChan
 
 		if !ok {
-			maxWheelChan <- wordCountForWheel{wheel:maxWheel, wordsCount:maxCount}
 			return
 		}
 
 		letterCounts := countLetters(currentWheel)
 
-		for mainLetter := 0; mainLetter < 26; mainLetter++ {
-			var compressedLetterCounts []int_tree.LetterCount
-
-			letterCounts[mainLetter]++
-
-			for letter, count := range letterCounts {
-				if count > 0 {
-					compressedLetterCounts = append(compressedLetterCounts, int_tree.LetterCount{
-						Letter: letter,
-						Count:  count,
-					})
-				}
-			}
+		counts := countWordsForCentres(index, objective, letterCounts)
 
-			wheelCount := 0
-			wheel := Wheel{mainLetter, compressedLetterCounts}
-			findWordsForWheel(trie, 0, wheel, &wheelCount)
-
-			if wheelCount > maxCount {
-				maxCount = wheelCount
-				maxWheel = wheel
+		for mainLetter := 0; mainLetter < 26; mainLetter++ {
+			// Only wheels that could be kept are worth building
+			if worst, full := best.worst(); full && counts[mainLetter] < worst {
+				stats <- true
+				continue
 			}
 
+			letterCounts[mainLetter]++
+			best.offer(scoredWheel{wheel: wheelFromCounts(mainLetter, letterCounts), score: counts[mainLetter]})
 			letterCounts[mainLetter]--
 
 			stats <- true
 		}
+
+		if score, full := best.worst(); floor != nil && full {
+			floor.raise(score)
+		}
+
+		processed.Done()
 	}
 }
 
-func combinationRepetitionUtil(wheelChan chan<- [WHEEL_SIZE - 1]int, chosen [WHEEL_SIZE - 1]int, index, r, start, end int) {
+/**
+What combinationRepetitionUtil does with the rings it generates. prune is asked about every prefix before its rings
+are generated and, if it returns true, they're skipped. rings is how many of the rings the prefix stands for would
+have been emitted
+ */
+type ringWalk struct {
+	emit  func([]int)
+	prune func(prefix []int, start, rings int) bool
+}
+
+/**
+How many rings the remaining letters of a prefix can make when they're chosen from letters letters
+ */
+func ringsFrom(letters, remaining int) int {
+	return binomial(letters+remaining-1, remaining)
+}
+
+func combinationRepetitionUtil(walk *ringWalk, chosen []int, index, r, start, end int) {
 	// Since index has become r, current combination is complete
 	if index == r {
-		wheelChan <- chosen
+		walk.emit(append([]int(nil), chosen...))
+		return
+	}
+
+	if index > 0 && walk.prune != nil && walk.prune(chosen[:index], start, ringsFrom(end-start+1, r-index)) {
 		return
 	}
 
 	// One by one choose all elements (without considering if the element is already chosen or not)
 	for i := start; i <= end; i++ {
 		chosen[index] = i
-		combinationRepetitionUtil(wheelChan, chosen, index+1, r, i, end)
+		combinationRepetitionUtil(walk, chosen, index+1, r, i, end)
+	}
+}
+
+/**
+Orders rings the same way combinationRepetition produces them
+ */
+func compareRings(a []int, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
 	}
+	return len(a) - len(b)
 }
 
-// Recursively calculates all combinations of the alphabet for the last 8 chars of a wheel.
-// We then iterate the alphabet and append the combination 26 times
-func combinationRepetition(wheelChan chan [WHEEL_SIZE - 1]int) {
-	var chosen [WHEEL_SIZE - 1]int
+// Recursively calculates all combinations of the layout's letters for the outer ring of a wheel.
+// We then iterate the alphabet and append the combination 26 times.
+// Only rings in the shard are emitted, and of those the ones up to and including after are skipped so a search
+// can carry on from a checkpoint. prune, which can be nil, is asked about prefixes whose rings all come after after
+func combinationRepetition(emit func([]int), prune func(prefix []int, start, rings int) bool, layout wheelLayout, after []int, part shard) {
+	chosen := make([]int, layout.outer())
+	walk := &ringWalk{emit: emit, prune: prune}
+
+	if after != nil {
+		next := walk.emit
+		passed := false
+
+		walk.emit = func(ring []int) {
+			passed = passed || compareRings(ring, after) > 0
+			if passed {
+				next(ring)
+			}
+		}
 
-	if testMode {
-		combinationRepetitionUtil(wheelChan, chosen, 0, WHEEL_SIZE-2, 0, 14)
-	} else {
-		combinationRepetitionUtil(wheelChan, chosen, 0, WHEEL_SIZE-2, 0, 26-1)
+		// Prefixes whose rings all come before after can be skipped outright, ones that straddle it are left to
+		// emit so none of the rings already searched are counted again
+		nextPrune := walk.prune
+		walk.prune = func(prefix []int, start, rings int) bool {
+			ring := append(append([]int(nil), prefix...), make([]int, layout.outer()-len(prefix))...)
+
+			for i := len(prefix); i < len(ring); i++ {
+				ring[i] = layout.letters - 1
+			}
+			if compareRings(ring, after) <= 0 {
+				return true
+			}
+
+			for i := len(prefix); i < len(ring); i++ {
+				ring[i] = start
+			}
+			return compareRings(ring, after) > 0 && nextPrune != nil && nextPrune(prefix, start, rings)
+		}
 	}
+
+	if part.count > 0 {
+		next := walk.emit
+		nextPrune := walk.prune
+		ring := 0
+
+		walk.emit = func(chosen []int) {
+			if part.includes(ring) {
+				next(chosen)
+			}
+			ring++
+		}
+
+		if nextPrune != nil {
+			walk.prune = func(prefix []int, start, rings int) bool {
+				if !nextPrune(prefix, start, part.within(ring, rings)) {
+					return false
+				}
+				ring += rings
+				return true
+			}
+		}
+	}
+
+	combinationRepetitionUtil(walk, chosen, 0, layout.outer(), 0, layout.letters-1)
 }
 
-func printOutput(solution wordCountForWheel) {
+/**
+Draws the wheel with its score, objective is how it was scored, see objective.String
+ */
+func printOutput(solution scoredWheel, objective string) {
 	wheel := solution.wheel
 
 	var letters []string
 	for _, letterCounts := range wheel.LetterCounts {
 		if letterCounts.Letter == wheel.MainLetter {
 			for j := 0; j < int(letterCounts.Count-1); j++ {
-				letters = append(letters, string(int_tree.Alphabet[letterCounts.Letter]))
+				letters = append(letters, string(trie.English.Letter(letterCounts.Letter)))
 			}
 		} else {
 			for j := 0; j < int(letterCounts.Count); j++ {
-				letters = append(letters, string(int_tree.Alphabet[letterCounts.Letter]))
+				letters = append(letters, string(trie.English.Letter(letterCounts.Letter)))
 			}
 		}
 	}
 
-	fmt.Printf("\n┏━━━━━━━━━━━┓\n")
-	fmt.Printf("┃ %s   %s   %s ┃\n", letters[0], letters[1], letters[2])
-	fmt.Printf("┃   ┏━━━┓   ┃\n")
-	fmt.Printf("┃ %s ┃ %s ┃ %s ┃  Found %d\n", letters[7], string(int_tree.Alphabet[wheel.MainLetter]), letters[3], solution.wordsCount)
-	fmt.Printf("┃   ┗━━━┛   ┃\n")
-	fmt.Printf("┃ %s   %s   %s ┃\n", letters[6], letters[5], letters[4])
-	fmt.Printf("┗━━━━━━━━━━━┛\n")
+	note := fmt.Sprintf("Found %d", solution.score)
+	if objective != DEFAULT_OBJECTIVE {
+		note = fmt.Sprintf("Scored %d on %s", solution.score, objective)
+	}
+
+	fmt.Printf("\n%s", render.Wheel(string(trie.English.Letter(wheel.MainLetter)), letters, note))
 }
 
-func findBestLetterWheel(trie int_tree.Node, details []int_tree.WordDetails) wordCountForWheel {
-	const NUM_CPUS = 8
+/**
+The flat trie is quicker to walk, the DAWG uses a fraction of the memory
+ */
+func newIndex(root *trie.Node, words []trie.WordDetails, useDAWG bool) (trie.Index, error) {
+	if useDAWG {
+		return trie.NewDAWG(root, words)
+	}
+	return trie.NewFlatTrie(root, words)
+}
+
+/**
+How findBestLetterWheel searches
+ */
+type searchOptions struct {
+	layout  wheelLayout
+	useDAWG bool
+	workers int
+	top     int
+
+	// Identifies the dictionary so a checkpoint isn't resumed against a different one, see dictionaryHash
+	dictionary string
+
+	// Where to save progress and how often, no checkpoints are written when checkpointFile is empty
+	checkpointFile  string
+	checkpointEvery time.Duration
+
+	// Carries on from a checkpoint instead of starting from the first ring
+	resume *checkpoint
+
+	// Only searches part of the rings, see shard
+	shard shard
+
+	// Skips rings and prefixes of rings that can't beat the wheels already kept, see wheelBound
+	prune bool
+
+	// What wheels are ranked on
+	objective objective
+}
+
+/**
+The best opts.top wheels, best first
+ */
+func findBestLetterWheels(root trie.Node, words []trie.WordDetails, opts searchOptions) []scoredWheel {
+	index, err := newIndex(&root, words, opts.useDAWG)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	progress := newCheckpoint(opts)
+	if opts.resume != nil {
+		if err := opts.resume.compatible(progress); err != nil {
+			log.Fatal(err)
+		}
+		progress = opts.resume
+	}
+
+	after, err := progress.position()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var resumed []scoredWheel
+	if resumed, err = importWheels(progress.Ranked); err != nil {
+		log.Fatal(err)
+	}
 
 	finished := make(chan bool)
-	maxWheelChan := make(chan wordCountForWheel)
-	outerWheelChan := make(chan [WHEEL_SIZE - 1]int, NUM_CPUS)
-	statUpdates := make(chan bool, NUM_CPUS)
+	outerWheelChan := make(chan []int, opts.workers)
+	statUpdates := make(chan bool, opts.workers)
 
-	go stats.PrintProgress(finished, statUpdates, TOTAL_WHEELS)
+	go stats.PrintProgress(finished, statUpdates, (opts.shard.size(opts.layout.totalRings())-progress.Searched-progress.Pruned)*26)
 
-	for i := 0; i < NUM_CPUS; i++ {
-		go findWords(&trie, outerWheelChan, statUpdates, maxWheelChan)
+	var floor *scoreFloor
+	if opts.prune {
+		floor = &scoreFloor{}
+		if len(resumed) == opts.top {
+			floor.raise(resumed[len(resumed)-1].score)
+		}
 	}
 
-	go func() {
-		combinationRepetition(outerWheelChan)
-		close(outerWheelChan)
-	}()
+	var processed, workers sync.WaitGroup
+	heaps := make([]*topWheels, opts.workers)
 
-	var maxSolution wordCountForWheel
+	for i := 0; i < opts.workers; i++ {
+		heaps[i] = newTopWheels(opts.top)
+		workers.Add(1)
 
-	for i := 0; i < NUM_CPUS; i++ {
-		select {
-		case solution := <-maxWheelChan:
-			if solution.wordsCount > maxSolution.wordsCount {
-				maxSolution = solution
+		go func(best *topWheels) {
+			findWords(index, opts.objective, outerWheelChan, statUpdates, best, &processed, floor)
+			workers.Done()
+		}(heaps[i])
+	}
+
+	var prune func(prefix []int, start, rings int) bool
+	if opts.prune {
+		prune = func(prefix []int, start, rings int) bool {
+			free := opts.layout.outer() - len(prefix)
+			if free > MAX_PRUNE_FREE_LETTERS || floor.get() == 0 || wheelBound(index, opts.objective, countLetters(prefix), free, start) >= floor.get() {
+				return false
+			}
+			progress.Pruned += rings
+
+			// Ticked off like searched wheels so the progress still counts up to the shard's total
+			for i := 0; i < rings*26; i++ {
+				statUpdates <- true
 			}
+			return true
+		}
+	}
+
+	// Every worker's heap merged with what was resumed. Only safe to call once processed has been waited on,
+	// as then no worker is touching its heap
+	merge := func() []scoredWheel {
+		lists := [][]scoredWheel{resumed}
+		for _, best := range heaps {
+			lists = append(lists, best.ranked())
+		}
+		return mergeRanked(lists, opts.top)
+	}
+
+	// Checkpoints are taken between rings, once every ring sent so far has been searched
+	save := func(ring []int, complete bool) {
+		processed.Wait()
+
+		progress.Ranked = exportedWheels(merge())
+		progress.Complete = complete
+		if ring != nil {
+			progress.setPosition(ring)
+		}
+
+		if err := saveCheckpoint(opts.checkpointFile, progress); err != nil {
+			log.Fatal(err)
 		}
 	}
 
+	var last []int
+
+	if !progress.Complete {
+		lastCheckpoint := time.Now()
+
+		combinationRepetition(func(ring []int) {
+			processed.Add(1)
+			progress.Searched++
+			outerWheelChan <- ring
+			last = ring
+
+			if opts.checkpointFile != "" && time.Since(lastCheckpoint) >= opts.checkpointEvery {
+				save(ring, false)
+				lastCheckpoint = time.Now()
+			}
+		}, prune, opts.layout, after, opts.shard)
+	}
+
+	close(outerWheelChan)
+	workers.Wait()
+
+	if opts.checkpointFile != "" {
+		save(last, true)
+	}
+
 	finished <- true
 
 	close(finished)
-	close(maxWheelChan)
 	close(statUpdates)
 
-	return maxSolution
+	return merge()
 }
 
-func findWordsForWheelClarification(wheel Wheel, words []int_tree.WordDetails) int {
+func findWordsForWheelClarification(wheel Wheel, words []trie.WordDetails) int {
 	count := 0
 
 	for _, word := range words {
-		if canWordBeSpeltFromWheel(word.SortedLetterCounts, wheel) {
+		if canWordBeSpeltFromWheel(&word, wheel) {
 			count++
 		}
 	}
@@ -233,37 +629,302 @@ func findWordsForWheelClarification(wheel Wheel, words []int_tree.WordDetails) i
 	return count
 }
 
-var testMode = false
+/**
+Lists the words a wheel can spell, most frequent first
+ */
+func wordsForWheel(wheel Wheel, words []trie.WordDetails) []*trie.WordDetails {
+	var matches []*trie.WordDetails
+
+	for i := range words {
+		if canWordBeSpeltFromWheel(&words[i], wheel) {
+			matches = append(matches, &words[i])
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Frequency > matches[j].Frequency
+	})
+
+	return matches
+}
+
+/**
+Identifies the words a search used, the source file and the options it was loaded with
+ */
+func dictionaryHash(filename string, opts trie.Options) (string, error) {
+	source, err := trie.HashFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	options := trie.OptionsHash(opts)
+	sum := sha256.Sum256(append(source[:], options[:]...))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func loadDictionary(filename string, dictionaryFlags trie.DictionaryFlags) (trie.Node, []trie.WordDetails, string) {
+	opts, err := dictionaryFlags.Options(context.Background(), trie.English)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	root, words, err := trie.OpenDictionary(context.Background(), filename, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hash, err := dictionaryHash(filename, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return root, words, hash
+}
+
+/**
+Starts the CPU profile and execution trace when their files are set, the returned func stops them
+ */
+func startProfiling(cpuProfile string, traceFile string) func() {
+	var stops []func()
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			log.Fatal("could not create CPU profile: ", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal("could not start CPU profile: ", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			log.Fatal("could not create trace: ", err)
+		}
+		if err := trace.Start(f); err != nil {
+			log.Fatal("could not start trace: ", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+		})
+	}
+
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
+
+func writeMemProfile(memProfile string) {
+	f, err := os.Create(memProfile)
+	if err != nil {
+		log.Fatal("could not create memory profile: ", err)
+	}
+	defer f.Close()
+
+	runtime.GC() // get up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Fatal("could not write memory profile: ", err)
+	}
+}
+
+/**
+letter-wheel merge [-top N] [-export file] shard-1.json shard-2.json ...
+ */
+func mergeMain(args []string) {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	top := flags.Int("top", 0, "number of best wheels to keep (default the same as the shards)")
+	export := flags.String("export", "", "write the ranked wheels to this file as JSON")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		log.Fatal("merge needs the result file of every shard")
+	}
+
+	var results []*checkpoint
+	for _, filename := range flags.Args() {
+		result, err := loadCheckpoint(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	ranked, searched, pruned, err := mergeShards(results, *top)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *export != "" {
+		if err := exportRanked(*export, ranked); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("Merged %d shards, %d wheels searched and %d pruned\n", len(results), searched*26, pruned*26)
+
+	if len(ranked) == 0 {
+		return
+	}
+
+	printOutput(ranked[0], results[0].Objective)
+	if len(ranked) > 1 {
+		printRanked(ranked)
+	}
+}
 
 func main() {
-	var trie int_tree.Node
-	var words []int_tree.WordDetails
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		mergeMain(os.Args[2:])
+		return
+	}
 
-	if testMode {
-		trie, words = int_tree.CreateIntDictionaryTree("./first_1000-3-to-9-letter-words.txt")
+	dictionary := flag.String("dictionary", "", "word list to search (default ./3-to-9-letter-words.txt, or ./first_1000-3-to-9-letter-words.txt with -test)")
+	dictionaryFlags := trie.AddDictionaryFlags(flag.CommandLine)
+	size := flag.Int("size", DEFAULT_WHEEL_SIZE, "letters in the wheel including the centre")
+	useDAWG := flag.Bool("dawg", false, "search a minimised automaton instead of the trie, slower but uses less memory")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of wheels searched at once")
+	testMode := flag.Bool("test", false, "only build outer rings from the first 15 letters and use a small dictionary")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the search to this file")
+	memProfile := flag.String("memprofile", "", "write a heap profile to this file once the search has finished")
+	traceFile := flag.String("trace", "", "write an execution trace of the search to this file")
+	top := flag.Int("top", 1, "number of best wheels to keep")
+	export := flag.String("export", "", "write the ranked wheels to this file as JSON")
+	checkpointFile := flag.String("checkpoint", "", "save progress to this file so the search can be resumed")
+	checkpointEvery := flag.Duration("checkpoint-every", time.Minute, "how often to save progress")
+	resumeFile := flag.String("resume", "", "carry on from this checkpoint")
+	prune := flag.Bool("prune", true, "skip outer rings that can't beat the wheels already kept")
+	shardFlag := flag.String("shard", "", "only search slice i of n, i.e. 2/4, saving the result to -checkpoint for merge")
+	objectiveFlag := flag.String("objective", DEFAULT_OBJECTIVE, "what wheels are scored on: words, min-frequency:F (words at least that frequent), frequency (summed, in thousandths), min-length:N (words at least N letters long) or solutions (words using every letter)")
+	strategy := flag.String("strategy", EXHAUSTIVE_STRATEGY, "how to search, one of "+strategyNames())
+	seed := flag.Int64("seed", 1, "random seed for the heuristic strategies")
+	iterations := flag.Int("iterations", 10000, "wheels a heuristic strategy scores before stopping, 0 for no limit")
+	budget := flag.Duration("time", 0, "how long a heuristic strategy runs for, 0 for no limit")
+	flag.Parse()
+
+	layout, err := newWheelLayout(*size, *testMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scoring, err := parseObjective(*objectiveFlag, layout.size)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *workers < 1 {
+		log.Fatalf("workers must be at least 1, got %d", *workers)
+	}
+	if *top < 1 {
+		log.Fatalf("top must be at least 1, got %d", *top)
+	}
+
+	var part shard
+	if *shardFlag != "" {
+		if part, err = parseShard(*shardFlag); err != nil {
+			log.Fatal(err)
+		}
+		// Every shard needs at least one ring or it would have no wheels to rank
+		if part.count > layout.totalRings() {
+			log.Fatalf("can't split %d rings into %d shards", layout.totalRings(), part.count)
+		}
+		if *checkpointFile == "" {
+			log.Fatal("shard needs -checkpoint to save its result to")
+		}
+	}
+
+	if *dictionary == "" {
+		*dictionary = "./3-to-9-letter-words.txt"
+		if *testMode {
+			*dictionary = "./first_1000-3-to-9-letter-words.txt"
+		}
+	}
+
+	root, words, hash := loadDictionary(*dictionary, dictionaryFlags)
+
+	if *strategy != EXHAUSTIVE_STRATEGY && (*shardFlag != "" || *checkpointFile != "" || *resumeFile != "") {
+		log.Fatal("only the exhaustive strategy can be sharded, checkpointed or resumed")
+	}
+
+	opts := searchOptions{
+		layout:          layout,
+		useDAWG:         *useDAWG,
+		workers:         *workers,
+		top:             *top,
+		dictionary:      hash,
+		checkpointFile:  *checkpointFile,
+		checkpointEvery: *checkpointEvery,
+		shard:           part,
+		prune:           *prune,
+		objective:       scoring,
+	}
+
+	if *resumeFile != "" {
+		if opts.resume, err = loadCheckpoint(*resumeFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var ranked []scoredWheel
+	var trajectory []trajectoryPoint
+
+	stopProfiling := startProfiling(*cpuProfile, *traceFile)
+	if *strategy == EXHAUSTIVE_STRATEGY {
+		ranked = findBestLetterWheels(root, words, opts)
 	} else {
-		trie, words = int_tree.CreateIntDictionaryTree("./3-to-9-letter-words.txt")
+		ranked, trajectory = findHeuristicWheels(root, words, heuristicOptions{
+			layout:     layout,
+			useDAWG:    *useDAWG,
+			top:        *top,
+			strategy:   *strategy,
+			seed:       *seed,
+			objective:  scoring,
+			iterations: *iterations,
+			budget:     *budget,
+		})
+	}
+	stopProfiling()
+
+	if *memProfile != "" {
+		writeMemProfile(*memProfile)
+	}
+
+	if len(ranked) == 0 {
+		log.Fatal("no wheels were scored, give the search a larger budget")
+	}
+
+	if *export != "" {
+		if err := exportRanked(*export, ranked); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	solution := findBestLetterWheel(trie, words)
+	solution := ranked[0]
+
 	clarificationWordCount := findWordsForWheelClarification(solution.wheel, words)
 
-	printOutput(solution)
+	printOutput(solution, opts.objective.String())
 	fmt.Printf("Clarification count found %d words\n", clarificationWordCount)
 
-	func(cpuProfile string) {
-		flag.Parse()
-		if cpuProfile != "" {
-			f, err := os.Create(cpuProfile)
-			if err != nil {
-				log.Fatal("could not create CPU profile: ", err)
-			}
-			defer f.Close()
-			if err := pprof.StartCPUProfile(f); err != nil {
-				log.Fatal("could not start CPU profile: ", err)
-			}
-			defer pprof.StopCPUProfile()
-		}
-	}("")
-	//}("cpu.prof")
+	var wheelWords []string
+	for _, word := range wordsForWheel(solution.wheel, words) {
+		wheelWords = append(wheelWords, word.Word)
+	}
+	fmt.Printf("Words: %s\n", strings.Join(wheelWords, ", "))
+
+	if len(ranked) > 1 {
+		printRanked(ranked)
+	}
+
+	if trajectory != nil {
+		printTrajectory(trajectory)
+	}
 }
@@ -2,27 +2,36 @@ package main
 
 import (
 	"os"
+	"sync"
 	"testing"
 
-	"github.com/joeyciechanowicz/letter-combinations/pkg/int-tree"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
 )
 
-var word = int_tree.NewWordDetails("hello")
+var word = trie.MustNewWordDetails(trie.English, "hello")
 
 /*
 a a a
 o e h
 l l e
  */
-var wheelWord = int_tree.NewWordDetails("aaaeehllo")
-var mainLetter = int_tree.ToAlphabetIndex(int_tree.ToRune("e"))
-var wheel = Wheel{mainLetter, wheelWord.SortedLetterCounts}
-var rawWheel = [WHEEL_SIZE - 1]int{0, 0, 0, 4, 7, 11, 11, 14}
+var wheelWord = trie.MustNewWordDetails(trie.English, "aaaeehllo")
+var mainLetter = trie.English.Index('e')
+var wheel = newWheel(mainLetter, wheelWord.SortedLetterCounts)
+var rawWheel = []int{0, 0, 0, 4, 7, 11, 11, 14}
 
-var trie int_tree.Node
+var flat *trie.FlatTrie
+var dawg *trie.DAWG
+var words []trie.WordDetails
+var root trie.Node
 
 func TestMain(m *testing.M) {
-	trie, _ = int_tree.CreateIntDictionaryTree("../../3-to-9-letter-words.txt")
+	// Build from the word list every time rather than reading or writing the user's cache
+	trie.CacheDir = ""
+
+	root, words = trie.Create("../../3-to-9-letter-words.txt", trie.English)
+	flat, _ = trie.NewFlatTrie(&root, words)
+	dawg, _ = trie.NewDAWG(&root, words)
 
 	code := m.Run()
 	os.Exit(code)
@@ -33,7 +42,7 @@ canWordBeSpeltFromWheel
  */
 
 func TestCanWordBeSpeltFromWheel(t *testing.T) {
-	canSpell := canWordBeSpeltFromWheel(word.SortedLetterCounts, wheel)
+	canSpell := canWordBeSpeltFromWheel(&word, wheel)
 
 	if !canSpell {
 		t.Errorf("Word could not be spelt.")
@@ -42,7 +51,7 @@ func TestCanWordBeSpeltFromWheel(t *testing.T) {
 
 func BenchmarkCanWordBeSpeltFromWheel(b *testing.B) {
 	for n := 0; n < b.N; n++ {
-		canWordBeSpeltFromWheel(word.SortedLetterCounts, wheel)
+		canWordBeSpeltFromWheel(&word, wheel)
 	}
 }
 
@@ -51,7 +60,16 @@ findWordsForWheel
  */
 func TestFindWordsForWheel(t *testing.T) {
 	var wheelCount = 0
-	findWordsForWheel(&trie, 0, wheel, &wheelCount)
+	findWordsForWheel(flat, flat.Root(), 0, wheel, &wheelCount)
+
+	if wheelCount != 15 {
+		t.Errorf("Count was incorrect, got: %d, want: %d.", wheelCount, 15)
+	}
+}
+
+func TestFindWordsForWheelDAWG(t *testing.T) {
+	var wheelCount = 0
+	findWordsForWheel(dawg, dawg.Root(), 0, wheel, &wheelCount)
 
 	if wheelCount != 15 {
 		t.Errorf("Count was incorrect, got: %d, want: %d.", wheelCount, 15)
@@ -61,7 +79,47 @@ func TestFindWordsForWheel(t *testing.T) {
 func BenchmarkFindWordsForWheel(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		var wheelCount = 0
-		findWordsForWheel(&trie, 0, wheel, &wheelCount)
+		findWordsForWheel(flat, flat.Root(), 0, wheel, &wheelCount)
+	}
+}
+
+func BenchmarkFindWordsForWheelDAWG(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var wheelCount = 0
+		findWordsForWheel(dawg, dawg.Root(), 0, wheel, &wheelCount)
+	}
+}
+
+/**
+The walk of the map trie the flat trie replaced, kept as a baseline for the benchmarks
+ */
+func findWordsForWheelMap(head *trie.Node, start int, currentWheel Wheel, wheelCount *int) {
+	for _, word := range head.Words {
+		if canWordBeSpeltFromWheel(word, currentWheel) {
+			*wheelCount++
+		}
+	}
+
+	for i := start; i < len(currentWheel.LetterCounts); i++ {
+		if child, ok := head.Children[currentWheel.LetterCounts[i].Letter]; ok {
+			findWordsForWheelMap(child, i+1, currentWheel, wheelCount)
+		}
+	}
+}
+
+func TestFindWordsForWheelMap(t *testing.T) {
+	var wheelCount = 0
+	findWordsForWheelMap(&root, 0, wheel, &wheelCount)
+
+	if wheelCount != 15 {
+		t.Errorf("Count was incorrect, got: %d, want: %d.", wheelCount, 15)
+	}
+}
+
+func BenchmarkFindWordsForWheelMap(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var wheelCount = 0
+		findWordsForWheelMap(&root, 0, wheel, &wheelCount)
 	}
 }
 
@@ -69,9 +127,11 @@ func BenchmarkFindWordsForWheel(b *testing.B) {
 findWords
  */
 func TestFindWords(t *testing.T) {
-	wheelChan := make(chan [WHEEL_SIZE - 1]int)
+	wheelChan := make(chan []int)
 	stats := make(chan bool)
-	maxWheelChan := make(chan wordCountForWheel)
+	best := newTopWheels(1)
+	var processed sync.WaitGroup
+	done := make(chan bool)
 
 	// Dump stats
 	go func() {
@@ -80,32 +140,83 @@ func TestFindWords(t *testing.T) {
 		}
 	}()
 
-	go findWords(&trie, wheelChan, stats, maxWheelChan)
+	go func() {
+		findWords(flat, wordCount{}, wheelChan, stats, best, &processed, nil)
+		done <- true
+	}()
 
+	processed.Add(1)
 	wheelChan <- rawWheel
 	close(wheelChan)
+	<-done
 
-	result := <-maxWheelChan
+	result := best.ranked()[0]
 
-	if result.wordsCount != 67 {
-		t.Errorf("Word count was incorrect, got: %d, want: %d.", result.wordsCount, 67)
+	if result.score != 67 {
+		t.Errorf("Word count was incorrect, got: %d, want: %d.", result.score, 67)
 	}
 
-	if int_tree.Alphabet[result.wheel.MainLetter] != rune("s"[0]) {
-		t.Errorf("Main letter was incorrect, got: %s, want: %s.", string(int_tree.Alphabet[result.wheel.MainLetter]), "s")
+	if trie.English.Letter(result.wheel.MainLetter) != rune("s"[0]) {
+		t.Errorf("Main letter was incorrect, got: %s, want: %s.", string(trie.English.Letter(result.wheel.MainLetter)), "s")
+	}
+}
+
+func TestCountWordsForCentres(t *testing.T) {
+	rings := [][]int{
+		rawWheel,
+		{4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4},
+		{0, 0, 4, 4, 8, 13, 17, 18, 18, 19, 19, 25},
+	}
+
+	layout, _ := newWheelLayout(5, true)
+	// A sample of test mode rings as well
+	ring := 0
+	combinationRepetition(func(chosen []int) {
+		if ring%97 == 0 {
+			rings = append(rings, chosen)
+		}
+		ring++
+	}, nil, layout, nil, shard{})
+
+	for _, ring := range rings {
+		letterCounts := countLetters(ring)
+		counts := countWordsForCentres(flat, wordCount{}, letterCounts)
+		if dawgCounts := countWordsForCentres(dawg, wordCount{}, letterCounts); dawgCounts != counts {
+			t.Errorf("DAWG counts for %v were incorrect, got: %v, want: %v.", ring, dawgCounts, counts)
+		}
+
+		for centre := 0; centre < 26; centre++ {
+			letterCounts[centre]++
+
+			var compressed []trie.LetterCount
+			for letter, count := range letterCounts {
+				if count > 0 {
+					compressed = append(compressed, trie.LetterCount{Letter: letter, Count: count})
+				}
+			}
+
+			wheelCount := 0
+			findWordsForWheel(flat, flat.Root(), 0, newWheel(centre, compressed), &wheelCount)
+			if counts[centre] != wheelCount {
+				t.Errorf("Count for %v centre %c was incorrect, got: %d, want: %d.", ring, trie.English.Letter(centre), counts[centre], wheelCount)
+			}
+
+			letterCounts[centre]--
+		}
 	}
 }
 
 func BenchmarkFindWords(b *testing.B) {
 	b.ReportAllocs()
 
-	wheelChan := make(chan [WHEEL_SIZE - 1]int)
+	wheelChan := make(chan []int)
 	stats := make(chan bool)
-	maxWheelChan := make(chan wordCountForWheel)
+	var processed sync.WaitGroup
 
-	go findWords(&trie, wheelChan, stats, maxWheelChan)
+	go findWords(flat, wordCount{}, wheelChan, stats, newTopWheels(1), &processed, nil)
 
 	for i := 0; i < b.N; i++ {
+		processed.Add(1)
 		wheelChan <- rawWheel
 
 		for j := 0; j < 26; j++ {
@@ -114,4 +225,69 @@ func BenchmarkFindWords(b *testing.B) {
 	}
 
 	close(wheelChan)
-}
\ No newline at end of file
+}
+
+/**
+Every centre of rawWheel scored one at a time on the map trie, how findWords worked before the flat trie
+ */
+func BenchmarkFindWordsMap(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		letterCounts := countLetters(rawWheel)
+
+		for mainLetter := 0; mainLetter < 26; mainLetter++ {
+			letterCounts[mainLetter]++
+			var wheelCount = 0
+			findWordsForWheelMap(&root, 0, wheelFromCounts(mainLetter, letterCounts), &wheelCount)
+			letterCounts[mainLetter]--
+		}
+	}
+}
+
+/*
+wheelLayout
+ */
+func TestTotalWheels(t *testing.T) {
+	cases := []struct {
+		size  int
+		test  bool
+		total int
+	}{
+		{9, false, 13884156 * 26},
+		{7, false, 736281 * 26},
+		{3, true, 120 * 26},
+	}
+
+	for _, c := range cases {
+		layout, err := newWheelLayout(c.size, c.test)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if layout.totalWheels() != c.total {
+			t.Errorf("Total for size %d was incorrect, got: %d, want: %d.", c.size, layout.totalWheels(), c.total)
+		}
+
+		// Too many size 9 rings to list in a test
+		if c.size == 9 {
+			continue
+		}
+
+		count := 0
+		combinationRepetition(func(ring []int) {
+			if len(ring) != c.size-1 {
+				t.Fatalf("Ring length was incorrect, got: %d, want: %d.", len(ring), c.size-1)
+			}
+			count++
+		}, nil, layout, nil, shard{})
+
+		if count*26 != c.total {
+			t.Errorf("Rings for size %d were incorrect, got: %d, want: %d.", c.size, count*26, c.total)
+		}
+	}
+
+	if _, err := newWheelLayout(MAX_WHEEL_SIZE+1, false); err == nil {
+		t.Errorf("Expected an error for a wheel that's too big")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+const DEFAULT_OBJECTIVE = "words"
+
+/**
+What wheels are scored on. Every objective adds up a score for each word the wheel spells, never a negative one, so
+a wheel's score can't be more than the score of every word its letters could spell, see wheelBound
+ */
+type objective interface {
+	// What word adds to the score of any wheel that spells it
+	score(word *trie.WordDetails) int
+	// How the objective is given to -objective, also used to check checkpoints and shards searched the same way
+	String() string
+}
+
+/**
+Every word scores 1, the number of words the wheel spells
+ */
+type wordCount struct{}
+
+func (wordCount) score(word *trie.WordDetails) int {
+	return 1
+}
+
+func (wordCount) String() string {
+	return DEFAULT_OBJECTIVE
+}
+
+/**
+The number of words with at least minimum frequency, so wheels full of obscure words don't win
+ */
+type frequentWordCount struct {
+	minimum float64
+}
+
+func (o frequentWordCount) score(word *trie.WordDetails) int {
+	if word.Frequency >= o.minimum {
+		return 1
+	}
+	return 0
+}
+
+func (o frequentWordCount) String() string {
+	return "min-frequency:" + strconv.FormatFloat(o.minimum, 'g', -1, 64)
+}
+
+/**
+The sum of the frequencies of the words, in thousandths so scores stay whole and sum the same in any order.
+Dictionaries can give negative frequencies, those words score 0 rather than taking away from the wheel. Raw corpus
+counts can be large enough to overflow, so each word scores at most MAX_FREQUENCY_SCORE
+ */
+type frequencySum struct{}
+
+// Low enough that a wheel spelling 2^24 words, more than any dictionary has, can't overflow its sum
+const MAX_FREQUENCY_SCORE = math.MaxInt >> 24
+
+func (frequencySum) score(word *trie.WordDetails) int {
+	if word.Frequency <= 0 {
+		return 0
+	}
+	if word.Frequency*1000 >= MAX_FREQUENCY_SCORE {
+		return MAX_FREQUENCY_SCORE
+	}
+	return int(math.Round(word.Frequency * 1000))
+}
+
+func (frequencySum) String() string {
+	return "frequency"
+}
+
+/**
+The number of words with at least minimum letters
+ */
+type longWordCount struct {
+	minimum int
+}
+
+func (o longWordCount) score(word *trie.WordDetails) int {
+	if wordLength(word) >= o.minimum {
+		return 1
+	}
+	return 0
+}
+
+func (o longWordCount) String() string {
+	return "min-length:" + strconv.Itoa(o.minimum)
+}
+
+/**
+The number of words that use every letter of a wheel of size letters, i.e. the 9 letter solutions of a 9 letter wheel
+ */
+type solutionCount struct {
+	size int
+}
+
+func (o solutionCount) score(word *trie.WordDetails) int {
+	if wordLength(word) == o.size {
+		return 1
+	}
+	return 0
+}
+
+func (o solutionCount) String() string {
+	return "solutions"
+}
+
+func wordLength(word *trie.WordDetails) int {
+	length := 0
+	for _, letterCount := range word.SortedLetterCounts {
+		length += int(letterCount.Count)
+	}
+	return length
+}
+
+/**
+Parses an -objective, one of words, min-frequency:F, frequency, min-length:N or solutions. size is the wheel size,
+which solutions need to know
+ */
+func parseObjective(value string, size int) (objective, error) {
+	name, argument, hasArgument := strings.Cut(value, ":")
+
+	switch {
+	case name == "words" && !hasArgument:
+		return wordCount{}, nil
+	case name == "frequency" && !hasArgument:
+		return frequencySum{}, nil
+	case name == "solutions" && !hasArgument:
+		return solutionCount{size}, nil
+	case name == "min-frequency" && hasArgument:
+		minimum, err := strconv.ParseFloat(argument, 64)
+		if err != nil {
+			return nil, fmt.Errorf("min-frequency objective needs a number, got %q", argument)
+		}
+		return frequentWordCount{minimum}, nil
+	case name == "min-length" && hasArgument:
+		minimum, err := strconv.Atoi(argument)
+		if err != nil || minimum < 1 {
+			return nil, fmt.Errorf("min-length objective needs a positive whole number, got %q", argument)
+		}
+		return longWordCount{minimum}, nil
+	}
+
+	return nil, fmt.Errorf("unknown objective %q, expected words, min-frequency:F, frequency, min-length:N or solutions", value)
+}
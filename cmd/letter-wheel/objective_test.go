@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+const frequencyDictionary = "tale\t10\nlate\t0.5\nteal\t2\ntea\t100\neat\t50\nate\t1\nslate\t3\nstale\t0.004\nsteal\t7\nleast\t20\ntales\t1\nlast\t40\nsalt\t30\nseat\t9\nsee\t60\n"
+
+func frequencyIndex(t *testing.T) (trie.Node, *trie.FlatTrie) {
+	node, words, err := trie.Read(context.Background(), strings.NewReader(frequencyDictionary), trie.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := trie.NewFlatTrie(&node, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return node, index
+}
+
+func TestParseObjective(t *testing.T) {
+	for _, value := range []string{"words", "min-frequency:10", "frequency", "min-length:5", "solutions"} {
+		parsed, err := parseObjective(value, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.String() != value {
+			t.Errorf("Objective was incorrect, got: %s, want: %s.", parsed.String(), value)
+		}
+	}
+
+	for _, value := range []string{"", "letters", "min-frequency", "min-frequency:lots", "min-length:0", "words:2"} {
+		if _, err := parseObjective(value, 9); err == nil {
+			t.Errorf("Expected an error for objective %q", value)
+		}
+	}
+}
+
+func TestFrequencySumNegative(t *testing.T) {
+	word := trie.MustNewWordDetails(trie.English, "tale")
+	word.Frequency = -5
+
+	if score := (frequencySum{}).score(&word); score != 0 {
+		t.Errorf("Score was incorrect, got: %d, want: %d.", score, 0)
+	}
+}
+
+func TestFrequencySumLarge(t *testing.T) {
+	word := trie.MustNewWordDetails(trie.English, "tale")
+
+	for _, frequency := range []float64{1e20, math.Inf(1)} {
+		word.Frequency = frequency
+		if score := (frequencySum{}).score(&word); score != MAX_FREQUENCY_SCORE {
+			t.Errorf("Score for %g was incorrect, got: %d, want: %d.", frequency, score, MAX_FREQUENCY_SCORE)
+		}
+	}
+
+	// Corpus counts summed over a wheel
+	node, words, err := trie.Read(context.Background(), strings.NewReader("tale\t1e300\nlate\t1e300\nteal\t1e300\n"), trie.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, _ := trie.NewFlatTrie(&node, words)
+
+	score := 0
+	scoreWordsForWheel(index, index.Root(), 0, wheelFromCounts(trie.English.Index('t'), countLetters([]int{0, 4, 11, 19})), frequencySum{}, &score)
+	if score != 3*MAX_FREQUENCY_SCORE {
+		t.Errorf("Wheel score was incorrect, got: %d, want: %d.", score, 3*MAX_FREQUENCY_SCORE)
+	}
+}
+
+func TestObjectiveScores(t *testing.T) {
+	_, index := frequencyIndex(t)
+
+	// Every word but see fits, and they all use the centre
+	letterCounts := countLetters([]int{0, 4, 11, 18})
+	letterCounts[19]++
+	centreWheel := wheelFromCounts(19, letterCounts)
+
+	cases := []struct {
+		objective string
+		score     int
+	}{
+		{"words", 14},
+		{"min-frequency:10", 6},
+		{"frequency", 273504},
+		{"min-length:5", 5},
+		{"solutions", 5},
+	}
+
+	for _, c := range cases {
+		scoring, _ := parseObjective(c.objective, 5)
+
+		score := 0
+		scoreWordsForWheel(index, index.Root(), 0, centreWheel, scoring, &score)
+		if score != c.score {
+			t.Errorf("%s score was incorrect, got: %d, want: %d.", c.objective, score, c.score)
+		}
+	}
+}
+
+func TestObjectivesScoreEveryCentre(t *testing.T) {
+	_, index := frequencyIndex(t)
+	layout, _ := newWheelLayout(5, false)
+
+	for _, value := range []string{"words", "min-frequency:10", "frequency", "min-length:5", "solutions"} {
+		scoring, _ := parseObjective(value, 5)
+
+		combinationRepetition(func(ring []int) {
+			letterCounts := countLetters(ring)
+			counts := countWordsForCentres(index, scoring, letterCounts)
+			bound := wheelBound(index, scoring, letterCounts, 0, 0)
+
+			for centre := 0; centre < 26; centre++ {
+				letterCounts[centre]++
+
+				score := 0
+				scoreWordsForWheel(index, index.Root(), 0, wheelFromCounts(centre, letterCounts), scoring, &score)
+				if counts[centre] != score {
+					t.Errorf("%s score for %v centre %c was incorrect, got: %d, want: %d.", value, ring, trie.English.Letter(centre), counts[centre], score)
+				}
+				if score > bound {
+					t.Errorf("%s bound for %v was incorrect, got: %d, want at least: %d.", value, ring, bound, score)
+				}
+
+				letterCounts[centre]--
+			}
+		}, nil, layout, nil, shard{})
+	}
+}
+
+func TestPrunedSearchWithObjective(t *testing.T) {
+	node, index := frequencyIndex(t)
+	layout, _ := newWheelLayout(5, false)
+
+	for _, value := range []string{"frequency", "min-length:5"} {
+		scoring, _ := parseObjective(value, 5)
+		opts := searchOptions{layout: layout, workers: 2, top: 3, dictionary: "test", objective: scoring}
+
+		exhaustive := findBestLetterWheels(node, index.Dictionary(), opts)
+		opts.prune = true
+		pruned := findBestLetterWheels(node, index.Dictionary(), opts)
+
+		for i := range exhaustive {
+			if pruned[i].wheel.key() != exhaustive[i].wheel.key() || pruned[i].score != exhaustive[i].score {
+				t.Errorf("%s rank %d was incorrect, got: %s %d, want: %s %d.", value, i+1, pruned[i].wheel.key(), pruned[i].score, exhaustive[i].wheel.key(), exhaustive[i].score)
+			}
+		}
+
+		ranked, _ := findHeuristicWheels(node, index.Dictionary(), heuristicOptions{layout: layout, top: 1, strategy: "hill-climbing", seed: 1, iterations: 500, objective: scoring})
+		if ranked[0].score > exhaustive[0].score {
+			t.Errorf("%s heuristic beat the exhaustive search, got: %d, want at most: %d.", value, ranked[0].score, exhaustive[0].score)
+		}
+	}
+}
+
+func TestCheckpointObjective(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, top: 5, dictionary: "test", objective: wordCount{}}
+
+	other := newCheckpoint(opts)
+	other.Objective = frequencySum{}.String()
+	if err := other.compatible(newCheckpoint(opts)); err == nil {
+		t.Errorf("Expected an error for a different objective")
+	}
+}
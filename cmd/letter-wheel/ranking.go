@@ -0,0 +1,155 @@
+package main
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+/**
+The wheel's letters in canonical order, its centre then every letter sorted, i.e. "e:aaaeehllo"
+ */
+func (wheel Wheel) key() string {
+	var key strings.Builder
+
+	key.WriteRune(trie.English.Letter(wheel.MainLetter))
+	key.WriteString(":")
+	for _, letterCount := range wheel.LetterCounts {
+		key.WriteString(strings.Repeat(string(trie.English.Letter(letterCount.Letter)), int(letterCount.Count)))
+	}
+
+	return key.String()
+}
+
+/**
+A higher score wins, ties go to the wheel that comes first in canonical order so results don't depend on which worker
+found them first
+ */
+func (result scoredWheel) beats(other scoredWheel) bool {
+	if result.score != other.score {
+		return result.score > other.score
+	}
+	return result.wheel.key() < other.wheel.key()
+}
+
+/**
+A min-heap with the worst kept wheel on top, so it can be swapped out when a better one is found
+ */
+type wheelHeap []scoredWheel
+
+func (h wheelHeap) Len() int           { return len(h) }
+func (h wheelHeap) Less(i, j int) bool { return h[j].beats(h[i]) }
+func (h wheelHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *wheelHeap) Push(x any)        { *h = append(*h, x.(scoredWheel)) }
+func (h *wheelHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+/**
+Keeps the best size wheels offered to it
+ */
+type topWheels struct {
+	size int
+	heap wheelHeap
+}
+
+func newTopWheels(size int) *topWheels {
+	return &topWheels{size: size}
+}
+
+func (top *topWheels) offer(result scoredWheel) {
+	if len(top.heap) < top.size {
+		heap.Push(&top.heap, result)
+		return
+	}
+
+	if result.beats(top.heap[0]) {
+		top.heap[0] = result
+		heap.Fix(&top.heap, 0)
+	}
+}
+
+/**
+The score of the worst kept wheel, and whether size wheels have been kept yet
+ */
+func (top *topWheels) worst() (int, bool) {
+	if len(top.heap) < top.size {
+		return 0, false
+	}
+	return top.heap[0].score, true
+}
+
+/**
+The kept wheels, best first
+ */
+func (top *topWheels) ranked() []scoredWheel {
+	ranked := append([]scoredWheel(nil), top.heap...)
+	sortRanked(ranked)
+	return ranked
+}
+
+func sortRanked(results []scoredWheel) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].beats(results[j])
+	})
+}
+
+/**
+Merges the ranked lists of every worker into the best size overall. The order is the same however the wheels were
+split between the lists
+ */
+func mergeRanked(lists [][]scoredWheel, size int) []scoredWheel {
+	var merged []scoredWheel
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+
+	sortRanked(merged)
+	if len(merged) > size {
+		merged = merged[:size]
+	}
+
+	return merged
+}
+
+type exportedWheel struct {
+	Rank    int    `json:"rank"`
+	Centre  string `json:"centre"`
+	Letters string `json:"letters"`
+	Score   int    `json:"score"`
+}
+
+func exportedWheels(ranked []scoredWheel) []exportedWheel {
+	var wheels []exportedWheel
+
+	for i, result := range ranked {
+		centre, letters, _ := strings.Cut(result.wheel.key(), ":")
+		wheels = append(wheels, exportedWheel{i + 1, centre, letters, result.score})
+	}
+
+	return wheels
+}
+
+func exportRanked(filename string, ranked []scoredWheel) error {
+	data, err := json.MarshalIndent(exportedWheels(ranked), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+func printRanked(ranked []scoredWheel) {
+	fmt.Printf("\nRank  Centre  Letters  Score\n")
+	for _, wheel := range exportedWheels(ranked) {
+		fmt.Printf("%4d  %6s  %s  %d\n", wheel.Rank, wheel.Centre, wheel.Letters, wheel.Score)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+func rankedWheel(centre rune, letters string, count int) scoredWheel {
+	details := trie.MustNewWordDetails(trie.English, letters)
+	return scoredWheel{newWheel(trie.English.Index(centre), details.SortedLetterCounts), count}
+}
+
+func TestTopWheels(t *testing.T) {
+	results := []scoredWheel{
+		rankedWheel('a', "abc", 3),
+		rankedWheel('b', "abc", 5),
+		rankedWheel('c', "abc", 5),
+		rankedWheel('a', "abd", 5),
+		rankedWheel('a', "abe", 1),
+		rankedWheel('a', "abf", 4),
+	}
+	want := []string{"a:abd", "b:abc", "c:abc", "a:abf"}
+
+	// Every split of the results between two workers should give the same list
+	for split := 0; split <= len(results); split++ {
+		first, second := newTopWheels(4), newTopWheels(4)
+		for i, result := range results {
+			if i < split {
+				first.offer(result)
+			} else {
+				second.offer(result)
+			}
+		}
+
+		merged := mergeRanked([][]scoredWheel{second.ranked(), first.ranked()}, 4)
+		if len(merged) != len(want) {
+			t.Fatalf("Length was incorrect, got: %d, want: %d.", len(merged), len(want))
+		}
+		for i, result := range merged {
+			if result.wheel.key() != want[i] {
+				t.Errorf("Rank %d for split %d was incorrect, got: %s, want: %s.", i+1, split, result.wheel.key(), want[i])
+			}
+		}
+	}
+}
+
+func TestExportedWheels(t *testing.T) {
+	exported := exportedWheels([]scoredWheel{rankedWheel('e', "aaaeehllo", 15)})
+
+	if exported[0] != (exportedWheel{1, "e", "aaaeehllo", 15}) {
+		t.Errorf("Export was incorrect, got: %v.", exported[0])
+	}
+}
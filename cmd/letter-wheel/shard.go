@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+)
+
+/**
+A deterministic slice of the search, every count-th ring starting from the index-th (both 0 based here, 1 based on
+the command line). The zero value is the whole search
+ */
+type shard struct {
+	index int
+	count int
+}
+
+func parseShard(value string) (shard, error) {
+	var index, count int
+	if _, err := fmt.Sscanf(value, "%d/%d", &index, &count); err != nil || count < 1 || index < 1 || index > count {
+		return shard{}, fmt.Errorf("shard should be i/n with 1 <= i <= n, got %q", value)
+	}
+	return shard{index - 1, count}, nil
+}
+
+func (s shard) String() string {
+	if s.count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.index+1, s.count)
+}
+
+func (s shard) includes(ring int) bool {
+	return s.count == 0 || ring%s.count == s.index
+}
+
+/**
+How many of total rings the shard searches
+ */
+func (s shard) size(total int) int {
+	if s.count == 0 {
+		return total
+	}
+	return (total - s.index + s.count - 1) / s.count
+}
+
+/**
+How many of the rings numbered from first to first+rings-1 are in the shard
+ */
+func (s shard) within(first, rings int) int {
+	if s.count == 0 {
+		return rings
+	}
+	return s.size(first+rings) - s.size(first)
+}
+
+/**
+Combines the results of every shard of a search into the ranked wheels of the whole search. Shards have to be
+complete, from the same dictionary and layout, and between them cover every ring exactly once. Also returns how many
+rings were searched and how many pruned
+ */
+func mergeShards(results []*checkpoint, top int) ([]scoredWheel, int, int, error) {
+	if len(results) == 0 {
+		return nil, 0, 0, fmt.Errorf("no shards to merge")
+	}
+
+	first := results[0]
+	seen := make(map[int]bool)
+	count := 0
+	searched, pruned := 0, 0
+	var lists [][]scoredWheel
+
+	for _, result := range results {
+		switch {
+		case result.Version != first.Version:
+			return nil, 0, 0, fmt.Errorf("shard %s has version %d, expected %d", result.Shard, result.Version, first.Version)
+		case result.Dictionary != first.Dictionary:
+			return nil, 0, 0, fmt.Errorf("shard %s was searched with a different dictionary to shard %s", result.Shard, first.Shard)
+		case result.Size != first.Size || result.Letters != first.Letters:
+			return nil, 0, 0, fmt.Errorf("shard %s is for %d letter wheels from %d letters, not %d from %d", result.Shard, result.Size, result.Letters, first.Size, first.Letters)
+		case result.Objective != first.Objective:
+			return nil, 0, 0, fmt.Errorf("shard %s scored wheels on %s, not %s", result.Shard, result.Objective, first.Objective)
+		case result.Top != first.Top:
+			return nil, 0, 0, fmt.Errorf("shard %s kept the top %d wheels, not %d", result.Shard, result.Top, first.Top)
+		case !result.Complete:
+			return nil, 0, 0, fmt.Errorf("shard %s hasn't finished, resume it first", result.Shard)
+		}
+
+		part, err := parseShard(result.Shard)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if count == 0 {
+			count = part.count
+		}
+		if part.count != count {
+			return nil, 0, 0, fmt.Errorf("shard %s is from a search split %d ways, not %d", result.Shard, part.count, count)
+		}
+		if seen[part.index] {
+			return nil, 0, 0, fmt.Errorf("shard %s was given more than once", result.Shard)
+		}
+		seen[part.index] = true
+
+		wheels, err := importWheels(result.Ranked)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		lists = append(lists, wheels)
+		searched += result.Searched
+		pruned += result.Pruned
+	}
+
+	if len(seen) != count {
+		var missing []string
+		for i := 0; i < count; i++ {
+			if !seen[i] {
+				missing = append(missing, shard{i, count}.String())
+			}
+		}
+		return nil, 0, 0, fmt.Errorf("missing shards %v", missing)
+	}
+
+	layout := wheelLayout{first.Size, first.Letters}
+	if searched+pruned != layout.totalRings() {
+		return nil, 0, 0, fmt.Errorf("shards searched %d rings and pruned %d, expected %d between them", searched, pruned, layout.totalRings())
+	}
+
+	// Each shard only kept its own top wheels, so the merge can't rank any more than that
+	if top < 1 {
+		top = first.Top
+	}
+	if top > first.Top {
+		return nil, 0, 0, fmt.Errorf("shards only kept their top %d wheels, can't merge the top %d", first.Top, top)
+	}
+
+	return mergeRanked(lists, top), searched, pruned, nil
+}
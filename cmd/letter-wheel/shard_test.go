@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func runShards(t *testing.T, opts searchOptions, count int) []*checkpoint {
+	var results []*checkpoint
+
+	for i := 0; i < count; i++ {
+		opts.shard = shard{i, count}
+		opts.checkpointFile = filepath.Join(t.TempDir(), fmt.Sprintf("shard-%d.json", i+1))
+		findBestLetterWheels(root, words, opts)
+
+		result, err := loadCheckpoint(opts.checkpointFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func TestParseShard(t *testing.T) {
+	part, err := parseShard("2/4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.index != 1 || part.count != 4 || part.String() != "2/4" {
+		t.Errorf("Shard was incorrect, got: %d %d %s, want: %d %d %s.", part.index, part.count, part.String(), 1, 4, "2/4")
+	}
+
+	for _, value := range []string{"0/4", "5/4", "1/0", "two"} {
+		if _, err := parseShard(value); err == nil {
+			t.Errorf("Expected an error for shard %q", value)
+		}
+	}
+}
+
+func TestShardsCoverEveryRing(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	total := layout.totalRings()
+
+	seen := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		part := shard{i, 3}
+		emitted := 0
+
+		combinationRepetition(func(ring []int) {
+			seen[fmt.Sprint(ring)]++
+			emitted++
+		}, nil, layout, nil, part)
+
+		if emitted != part.size(total) {
+			t.Errorf("Shard %s size was incorrect, got: %d, want: %d.", part, emitted, part.size(total))
+		}
+	}
+
+	if len(seen) != total {
+		t.Errorf("Rings was incorrect, got: %d, want: %d.", len(seen), total)
+	}
+	for ring, times := range seen {
+		if times != 1 {
+			t.Errorf("Ring %s was searched %d times, want: 1.", ring, times)
+		}
+	}
+}
+
+func TestMergeShards(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, workers: 2, top: 5, dictionary: "test", objective: wordCount{}}
+
+	whole := findBestLetterWheels(root, words, opts)
+	results := runShards(t, opts, 3)
+
+	// The order shards are given in doesn't matter
+	results[0], results[2] = results[2], results[0]
+
+	merged, searched, pruned, err := mergeShards(results, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if searched+pruned != layout.totalRings() {
+		t.Errorf("Searched and pruned were incorrect, got: %d + %d, want: %d.", searched, pruned, layout.totalRings())
+	}
+	if len(merged) != len(whole) {
+		t.Fatalf("Length was incorrect, got: %d, want: %d.", len(merged), len(whole))
+	}
+	for i := range merged {
+		if merged[i].wheel.key() != whole[i].wheel.key() || merged[i].score != whole[i].score {
+			t.Errorf("Rank %d was incorrect, got: %s %d, want: %s %d.", i+1, merged[i].wheel.key(), merged[i].score, whole[i].wheel.key(), whole[i].score)
+		}
+	}
+}
+
+func TestMergeRefusesMismatchedShards(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	opts := searchOptions{layout: layout, workers: 2, top: 5, dictionary: "test", objective: wordCount{}}
+	results := runShards(t, opts, 2)
+
+	other := *results[1]
+	other.Dictionary = "other"
+	if _, _, _, err := mergeShards([]*checkpoint{results[0], &other}, 0); err == nil {
+		t.Errorf("Expected an error for a different dictionary")
+	}
+
+	other = *results[1]
+	other.Objective = frequencySum{}.String()
+	if _, _, _, err := mergeShards([]*checkpoint{results[0], &other}, 0); err == nil {
+		t.Errorf("Expected an error for a different objective")
+	}
+
+	if _, _, _, err := mergeShards(results[:1], 0); err == nil {
+		t.Errorf("Expected an error for a missing shard")
+	}
+
+	if _, _, _, err := mergeShards([]*checkpoint{results[0], results[0]}, 0); err == nil {
+		t.Errorf("Expected an error for a repeated shard")
+	}
+
+	unfinished := *results[1]
+	unfinished.Complete = false
+	if _, _, _, err := mergeShards([]*checkpoint{results[0], &unfinished}, 0); err == nil {
+		t.Errorf("Expected an error for an unfinished shard")
+	}
+
+	short := *results[1]
+	short.Searched--
+	if _, _, _, err := mergeShards([]*checkpoint{results[0], &short}, 0); err == nil {
+		t.Errorf("Expected an error for shards that don't cover every ring")
+	}
+
+	if _, _, _, err := mergeShards(results, 10); err == nil {
+		t.Errorf("Expected an error for more wheels than the shards kept")
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+const EXHAUSTIVE_STRATEGY = "exhaustive"
+
+/**
+Heuristic searches, each moves between candidate wheels until the search's budget runs out
+ */
+var strategies = map[string]func(search *heuristicSearch){
+	"annealing":     anneal,
+	"hill-climbing": hillClimb,
+	"genetic":       evolve,
+}
+
+func strategyNames() string {
+	names := []string{EXHAUSTIVE_STRATEGY}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return strings.Join(names, ", ")
+}
+
+/**
+A wheel as the heuristics see it, a centre letter and the sorted letters of the outer ring
+ */
+type candidate struct {
+	centre int
+	outer  []int
+}
+
+func (c candidate) wheel() Wheel {
+	letterCounts := countLetters(c.outer)
+	letterCounts[c.centre]++
+	return wheelFromCounts(c.centre, letterCounts)
+}
+
+/**
+The best score found so far and when it was found
+ */
+type trajectoryPoint struct {
+	Iteration int
+	Elapsed   time.Duration
+	Score     int
+}
+
+type heuristicOptions struct {
+	layout   wheelLayout
+	useDAWG  bool
+	top      int
+	strategy string
+	seed     int64
+
+	objective objective
+
+	// The search stops at whichever runs out first, zero is no limit
+	iterations int
+	budget     time.Duration
+}
+
+type heuristicSearch struct {
+	index     trie.Index
+	objective objective
+	layout    wheelLayout
+	random    *rand.Rand
+
+	iterations int
+	limit      int
+	start      time.Time
+	budget     time.Duration
+
+	// Every wheel scored so far by key, so revisiting one is free and it's only offered to best once
+	scores     map[string]int
+	best       *topWheels
+	trajectory []trajectoryPoint
+}
+
+/**
+Runs opts.strategy, returning the best opts.top wheels it came across, best first, and how the best score improved
+ */
+func findHeuristicWheels(root trie.Node, words []trie.WordDetails, opts heuristicOptions) ([]scoredWheel, []trajectoryPoint) {
+	run, ok := strategies[opts.strategy]
+	if !ok {
+		log.Fatalf("unknown strategy %q, expected one of %s", opts.strategy, strategyNames())
+	}
+	if opts.iterations <= 0 && opts.budget <= 0 {
+		log.Fatal("a heuristic search needs an iteration or time budget")
+	}
+
+	index, err := newIndex(&root, words, opts.useDAWG)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	search := &heuristicSearch{
+		index:     index,
+		objective: opts.objective,
+		layout:    opts.layout,
+		random:    rand.New(rand.NewSource(opts.seed)),
+		limit:     opts.iterations,
+		start:     time.Now(),
+		budget:    opts.budget,
+		scores:    make(map[string]int),
+		best:      newTopWheels(opts.top),
+	}
+
+	run(search)
+
+	return search.best.ranked(), search.trajectory
+}
+
+func (search *heuristicSearch) done() bool {
+	return search.progress() >= 1
+}
+
+/**
+How much of the budget has been used, from 0 to 1
+ */
+func (search *heuristicSearch) progress() float64 {
+	progress := 0.0
+	if search.limit > 0 {
+		progress = float64(search.iterations) / float64(search.limit)
+	}
+	if search.budget > 0 {
+		progress = math.Max(progress, float64(time.Since(search.start))/float64(search.budget))
+	}
+	return progress
+}
+
+/**
+Scores a candidate with scoreWordsForWheel. Every call uses up an iteration, even if the wheel has been seen before
+ */
+func (search *heuristicSearch) score(c candidate) int {
+	search.iterations++
+
+	wheel := c.wheel()
+	key := wheel.key()
+
+	if score, ok := search.scores[key]; ok {
+		return score
+	}
+
+	score := 0
+	scoreWordsForWheel(search.index, search.index.Root(), 0, wheel, search.objective, &score)
+	search.scores[key] = score
+	search.best.offer(scoredWheel{wheel: wheel, score: score})
+
+	if len(search.trajectory) == 0 || score > search.trajectory[len(search.trajectory)-1].Score {
+		search.trajectory = append(search.trajectory, trajectoryPoint{search.iterations, time.Since(search.start), score})
+	}
+
+	return score
+}
+
+func (search *heuristicSearch) randomCandidate() candidate {
+	outer := make([]int, search.layout.outer())
+	for i := range outer {
+		outer[i] = search.random.Intn(search.layout.letters)
+	}
+	sort.Ints(outer)
+
+	return candidate{centre: search.random.Intn(trie.English.Size()), outer: outer}
+}
+
+/**
+A candidate one letter different to c, either in the outer ring or the centre
+ */
+func (search *heuristicSearch) neighbour(c candidate) candidate {
+	position := search.random.Intn(len(c.outer) + 1)
+
+	if position == len(c.outer) {
+		centre := (c.centre + 1 + search.random.Intn(trie.English.Size()-1)) % trie.English.Size()
+		return candidate{centre: centre, outer: c.outer}
+	}
+
+	outer := append([]int(nil), c.outer...)
+	outer[position] = (outer[position] + 1 + search.random.Intn(search.layout.letters-1)) % search.layout.letters
+	sort.Ints(outer)
+
+	return candidate{centre: c.centre, outer: outer}
+}
+
+/**
+Every candidate one letter different to c, in a random order
+ */
+func (search *heuristicSearch) neighbours(c candidate) []candidate {
+	var neighbours []candidate
+
+	for centre := 0; centre < trie.English.Size(); centre++ {
+		if centre != c.centre {
+			neighbours = append(neighbours, candidate{centre: centre, outer: c.outer})
+		}
+	}
+
+	for position := range c.outer {
+		// Changing any of a run of the same letter gives the same ring
+		if position > 0 && c.outer[position] == c.outer[position-1] {
+			continue
+		}
+
+		for letter := 0; letter < search.layout.letters; letter++ {
+			if letter != c.outer[position] {
+				outer := append([]int(nil), c.outer...)
+				outer[position] = letter
+				sort.Ints(outer)
+				neighbours = append(neighbours, candidate{centre: c.centre, outer: outer})
+			}
+		}
+	}
+
+	search.random.Shuffle(len(neighbours), func(i, j int) {
+		neighbours[i], neighbours[j] = neighbours[j], neighbours[i]
+	})
+
+	return neighbours
+}
+
+/**
+Random-restart hill climbing. Moves to the first neighbour that scores better until none do, then starts again from
+a random wheel
+ */
+func hillClimb(search *heuristicSearch) {
+	current := search.randomCandidate()
+	currentScore := search.score(current)
+
+	for !search.done() {
+		improved := false
+
+		for _, next := range search.neighbours(current) {
+			if search.done() {
+				return
+			}
+
+			if nextScore := search.score(next); nextScore > currentScore {
+				current, currentScore = next, nextScore
+				improved = true
+				break
+			}
+		}
+
+		if !improved && !search.done() {
+			current = search.randomCandidate()
+			currentScore = search.score(current)
+		}
+	}
+}
+
+// Moves taken at random before annealing to see how much neighbours' scores differ under the objective
+const ANNEALING_SAMPLES = 20
+
+// Chance of moving to a neighbour that's worse by the sampled difference, at the start and end of the budget
+const ANNEALING_START_ACCEPTANCE = 0.8
+const ANNEALING_END_ACCEPTANCE = 0.001
+
+/**
+Walks ANNEALING_SAMPLES random moves from c, returning where the walk ended, its score and the mean difference in score
+between each move's wheels. Scores are counts under some objectives and thousandths under others, so the annealing
+temperature is scaled by this rather than fixed
+ */
+func (search *heuristicSearch) sampleMoves(c candidate, score int) (candidate, int, float64) {
+	total, moves := 0.0, 0
+	for ; moves < ANNEALING_SAMPLES && !search.done(); moves++ {
+		next := search.neighbour(c)
+		nextScore := search.score(next)
+		total += math.Abs(float64(nextScore - score))
+		c, score = next, nextScore
+	}
+
+	// Every sampled neighbour scored the same, any scale will do until the walk finds a difference
+	if total == 0 {
+		return c, score, 1
+	}
+	return c, score, total / float64(moves)
+}
+
+/**
+The temperature at which a move worse by scale is taken with chance acceptance
+ */
+func temperatureFor(scale, acceptance float64) float64 {
+	return scale / -math.Log(acceptance)
+}
+
+/**
+Whether to move to a neighbour whose score differs by delta, better moves are always taken
+ */
+func (search *heuristicSearch) accept(delta int, temperature float64) bool {
+	return delta >= 0 || search.random.Float64() < math.Exp(float64(delta)/temperature)
+}
+
+/**
+Simulated annealing. Always moves to a better neighbour and to a worse one with a chance that shrinks over the budget
+from ANNEALING_START_ACCEPTANCE to ANNEALING_END_ACCEPTANCE for a move worse by the sampled difference
+ */
+func anneal(search *heuristicSearch) {
+	current := search.randomCandidate()
+	currentScore := search.score(current)
+
+	current, currentScore, scale := search.sampleMoves(current, currentScore)
+	start := temperatureFor(scale, ANNEALING_START_ACCEPTANCE)
+	end := temperatureFor(scale, ANNEALING_END_ACCEPTANCE)
+
+	for !search.done() {
+		temperature := start * math.Pow(end/start, search.progress())
+
+		next := search.neighbour(current)
+		nextScore := search.score(next)
+
+		if search.accept(nextScore-currentScore, temperature) {
+			current, currentScore = next, nextScore
+		}
+	}
+}
+
+const GENETIC_POPULATION = 50
+const GENETIC_ELITES = 2
+const GENETIC_TOURNAMENT = 3
+const GENETIC_MUTATION_RATE = 0.2
+
+type scoredCandidate struct {
+	candidate
+	score int
+}
+
+/**
+A genetic algorithm. Each generation keeps the GENETIC_ELITES best wheels and fills the rest by crossing over parents
+picked by tournament, taking the child's ring from the letters of both parents' rings and its centre from either,
+then sometimes mutating it
+ */
+func evolve(search *heuristicSearch) {
+	first := search.randomCandidate()
+	population := []scoredCandidate{{first, search.score(first)}}
+
+	for len(population) < GENETIC_POPULATION && !search.done() {
+		c := search.randomCandidate()
+		population = append(population, scoredCandidate{c, search.score(c)})
+	}
+
+	for !search.done() {
+		sort.SliceStable(population, func(i, j int) bool {
+			return population[i].score > population[j].score
+		})
+
+		next := append([]scoredCandidate(nil), population[:GENETIC_ELITES]...)
+
+		for len(next) < GENETIC_POPULATION && !search.done() {
+			child := search.crossover(search.tournament(population), search.tournament(population))
+			if search.random.Float64() < GENETIC_MUTATION_RATE {
+				child = search.neighbour(child)
+			}
+			next = append(next, scoredCandidate{child, search.score(child)})
+		}
+
+		population = next
+	}
+}
+
+func (search *heuristicSearch) tournament(population []scoredCandidate) candidate {
+	winner := population[search.random.Intn(len(population))]
+	for i := 1; i < GENETIC_TOURNAMENT; i++ {
+		if challenger := population[search.random.Intn(len(population))]; challenger.score > winner.score {
+			winner = challenger
+		}
+	}
+	return winner.candidate
+}
+
+func (search *heuristicSearch) crossover(a, b candidate) candidate {
+	letters := append(append([]int(nil), a.outer...), b.outer...)
+	search.random.Shuffle(len(letters), func(i, j int) {
+		letters[i], letters[j] = letters[j], letters[i]
+	})
+
+	outer := letters[:len(a.outer)]
+	sort.Ints(outer)
+
+	centre := a.centre
+	if search.random.Intn(2) == 1 {
+		centre = b.centre
+	}
+
+	return candidate{centre: centre, outer: outer}
+}
+
+func printTrajectory(trajectory []trajectoryPoint) {
+	fmt.Printf("\nIteration  Seconds  Best\n")
+	for _, point := range trajectory {
+		fmt.Printf("%9d  %7.2f  %d\n", point.Iteration, point.Elapsed.Seconds(), point.Score)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+func TestHeuristicStrategies(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	exhaustive := findBestLetterWheels(root, words, searchOptions{layout: layout, workers: 2, top: 1, dictionary: "test", objective: wordCount{}})
+
+	for name := range strategies {
+		opts := heuristicOptions{layout: layout, top: 3, strategy: name, seed: 7, iterations: 2000, objective: wordCount{}}
+
+		ranked, trajectory := findHeuristicWheels(root, words, opts)
+		again, againTrajectory := findHeuristicWheels(root, words, opts)
+
+		if len(ranked) != 3 || len(again) != 3 {
+			t.Fatalf("%s length was incorrect, got: %d %d, want: %d.", name, len(ranked), len(again), 3)
+		}
+
+		// The same seed and iteration budget always finds the same wheels
+		for i := range ranked {
+			if ranked[i].wheel.key() != again[i].wheel.key() {
+				t.Errorf("%s rank %d wasn't repeatable, got: %s, then: %s.", name, i+1, ranked[i].wheel.key(), again[i].wheel.key())
+			}
+		}
+		if len(trajectory) != len(againTrajectory) {
+			t.Errorf("%s trajectory wasn't repeatable, got: %d points, then: %d.", name, len(trajectory), len(againTrajectory))
+		}
+
+		for i := 1; i < len(trajectory); i++ {
+			if trajectory[i].Score <= trajectory[i-1].Score || trajectory[i].Iteration <= trajectory[i-1].Iteration {
+				t.Errorf("%s trajectory didn't improve at point %d, got: %d, after: %d.", name, i, trajectory[i].Score, trajectory[i-1].Score)
+			}
+		}
+
+		best := ranked[0]
+		if last := trajectory[len(trajectory)-1].Score; last != best.score {
+			t.Errorf("%s trajectory end was incorrect, got: %d, want: %d.", name, last, best.score)
+		}
+
+		wheelCount := 0
+		findWordsForWheel(flat, flat.Root(), 0, best.wheel, &wheelCount)
+		if wheelCount != best.score {
+			t.Errorf("%s score was incorrect, got: %d, want: %d.", name, best.score, wheelCount)
+		}
+
+		if best.score > exhaustive[0].score {
+			t.Errorf("%s beat the exhaustive search, got: %d, want at most: %d.", name, best.score, exhaustive[0].score)
+		}
+	}
+}
+
+func TestHeuristicStrategiesOutOfTime(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+
+	for name := range strategies {
+		// The budget runs out before the first check, every strategy still scores a wheel
+		ranked, _ := findHeuristicWheels(root, words, heuristicOptions{layout: layout, top: 1, strategy: name, budget: time.Nanosecond, objective: wordCount{}})
+		if len(ranked) != 1 {
+			t.Errorf("%s length was incorrect, got: %d, want: %d.", name, len(ranked), 1)
+		}
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	layout, _ := newWheelLayout(4, true)
+	search := &heuristicSearch{layout: layout, random: rand.New(rand.NewSource(1))}
+
+	c := candidate{centre: 4, outer: []int{0, 0, 11}}
+	neighbours := search.neighbours(c)
+
+	// 25 other centres, and the ring's two different letters each swapped for 14 others
+	if len(neighbours) != 25+2*14 {
+		t.Errorf("Neighbours was incorrect, got: %d, want: %d.", len(neighbours), 25+2*14)
+	}
+
+	for i := 0; i < 100; i++ {
+		next := search.neighbour(c)
+		changed := 0
+		if next.centre != c.centre {
+			changed++
+		}
+		if countLetters(next.outer) != countLetters(c.outer) {
+			changed++
+		}
+		if changed != 1 {
+			t.Errorf("Neighbour %v of %v changed %d parts, want: 1.", next, c, changed)
+		}
+	}
+}
+
+func TestAnnealingTemperature(t *testing.T) {
+	_, index := frequencyIndex(t)
+	layout, _ := newWheelLayout(5, false)
+	search := &heuristicSearch{
+		index:     index,
+		objective: frequencySum{},
+		layout:    layout,
+		random:    rand.New(rand.NewSource(1)),
+		limit:     1000,
+		start:     time.Now(),
+		scores:    make(map[string]int),
+		best:      newTopWheels(1),
+	}
+
+	// Frequencies are scored in thousandths, so neighbours differ by far more than a word count would
+	c := candidate{centre: trie.English.Index('e'), outer: []int{trie.English.Index('a'), trie.English.Index('l'), trie.English.Index('s'), trie.English.Index('t')}}
+	score := search.score(c)
+
+	_, _, scale := search.sampleMoves(c, score)
+	start := temperatureFor(scale, ANNEALING_START_ACCEPTANCE)
+	end := temperatureFor(scale, ANNEALING_END_ACCEPTANCE)
+
+	worse, acceptedEarly, acceptedLate := 0, 0, 0
+	for _, next := range search.neighbours(c) {
+		delta := search.score(next) - score
+		if delta >= 0 {
+			continue
+		}
+
+		worse++
+		if search.accept(delta, start) {
+			acceptedEarly++
+		}
+		if search.accept(delta, end) {
+			acceptedLate++
+		}
+	}
+
+	if worse == 0 {
+		t.Fatalf("Expected %v to have worse neighbours", c)
+	}
+	if acceptedEarly == 0 {
+		t.Errorf("Worse moves accepted early was incorrect, got: %d of %d, want more than 0.", acceptedEarly, worse)
+	}
+	if acceptedLate >= acceptedEarly {
+		t.Errorf("Worse moves accepted late was incorrect, got: %d, want fewer than: %d.", acceptedLate, acceptedEarly)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/pattern"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+	"log"
+	"os"
+	"sort"
+)
+
+/**
+Flags every subcommand shares for picking the dictionary
+ */
+type dictionaryFlags struct {
+	filename  *string
+	filters   trie.DictionaryFlags
+	rank      *bool
+	limit     *int
+	minLength *int
+	maxLength *int
+}
+
+func addDictionaryFlags(flags *flag.FlagSet) dictionaryFlags {
+	return dictionaryFlags{
+		filename:  flags.String("dictionary", "./3-to-9-letter-words.txt", "word list to search"),
+		filters:   trie.AddDictionaryFlags(flags),
+		rank:      flags.Bool("rank", false, "list the most frequent words first"),
+		limit:     flags.Int("limit", 0, "only list this many words, the most frequent with -rank, 0 for no limit"),
+		minLength: flags.Int("min-length", 0, "minimum word length in letters"),
+		maxLength: flags.Int("max-length", 0, "maximum word length in letters, 0 for no limit"),
+	}
+}
+
+func loadDictionary(dictionary dictionaryFlags) (trie.Node, []trie.WordDetails) {
+	opts, err := dictionary.filters.Options(context.Background(), trie.English)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	root, words, err := trie.OpenDictionary(context.Background(), *dictionary.filename, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return root, words
+}
+
+func loadIndex(dictionary dictionaryFlags) *trie.FlatTrie {
+	root, words := loadDictionary(dictionary)
+
+	flat, err := trie.NewFlatTrie(&root, words)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return flat
+}
+
+func countLetters(letters string) []trie.LetterCount {
+	if letters == "" {
+		return nil
+	}
+
+	details, err := trie.NewWordDetails(trie.English, letters)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return details.SortedLetterCounts
+}
+
+/**
+The limit to give a query. Ranking needs every word to find the most frequent, so printWords applies the limit instead
+ */
+func (dictionary dictionaryFlags) queryLimit() int {
+	if *dictionary.rank {
+		return 0
+	}
+	return *dictionary.limit
+}
+
+func parseLetters(flags *flag.FlagSet) []trie.LetterCount {
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return countLetters(flags.Arg(0))
+}
+
+func printWords(words []*trie.WordDetails, dictionary dictionaryFlags) {
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].Word < words[j].Word
+	})
+	if *dictionary.rank {
+		sort.SliceStable(words, func(i, j int) bool {
+			return words[i].Frequency > words[j].Frequency
+		})
+	}
+	if *dictionary.limit > 0 && len(words) > *dictionary.limit {
+		words = words[:*dictionary.limit]
+	}
+
+	for _, word := range words {
+		fmt.Println(word.Word)
+	}
+}
+
+func subanagrams(args []string) {
+	flags := flag.NewFlagSet("subanagrams", flag.ExitOnError)
+	dictionary := addDictionaryFlags(flags)
+	required := flags.String("required", "", "letters every word must use")
+	blanks := flags.Int("blanks", 0, "number of blank tiles")
+	flags.Parse(args)
+
+	query := trie.Query{
+		Letters:   parseLetters(flags),
+		Required:  countLetters(*required),
+		Blanks:    *blanks,
+		MinLength: *dictionary.minLength,
+		MaxLength: *dictionary.maxLength,
+		Limit:     dictionary.queryLimit(),
+	}
+
+	printWords(trie.SubAnagrams(loadIndex(dictionary), query), dictionary)
+}
+
+func superanagrams(args []string) {
+	flags := flag.NewFlagSet("superanagrams", flag.ExitOnError)
+	dictionary := addDictionaryFlags(flags)
+	maxExtra := flags.Int("max-extra", -1, "most letters a word can have beyond the given ones, -1 for no limit")
+	flags.Parse(args)
+
+	query := trie.SuperQuery{
+		Letters:   parseLetters(flags),
+		MaxExtra:  *maxExtra,
+		MinLength: *dictionary.minLength,
+		MaxLength: *dictionary.maxLength,
+		Limit:     dictionary.queryLimit(),
+	}
+
+	printWords(trie.Superanagrams(loadIndex(dictionary), query), dictionary)
+}
+
+/**
+Crossword style search, i.e. c?t??e or s*ing, optionally limited to the letters of a wheel
+ */
+func patternSearch(args []string) {
+	flags := flag.NewFlagSet("pattern", flag.ExitOnError)
+	dictionary := addDictionaryFlags(flags)
+	letters := flags.String("letters", "", "only use these letters, at most as often as they're given")
+	required := flags.String("required", "", "letters every word must use")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	p, err := pattern.Parse(trie.English, flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, words := loadDictionary(dictionary)
+	index, err := pattern.New(trie.English, words)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	query := pattern.Query{
+		Pattern:   p,
+		Letters:   countLetters(*letters),
+		Required:  countLetters(*required),
+		MinLength: *dictionary.minLength,
+		MaxLength: *dictionary.maxLength,
+		Limit:     dictionary.queryLimit(),
+	}
+
+	printWords(index.Search(query), dictionary)
+}
+
+var subcommands = map[string]func(args []string){
+	"subanagrams":   subanagrams,
+	"superanagrams": superanagrams,
+	"pattern":       patternSearch,
+}
+
+func main() {
+	if len(os.Args) < 2 || subcommands[os.Args[1]] == nil {
+		fmt.Fprintf(os.Stderr, "usage: word-search <subanagrams|superanagrams> [flags] <letters>\n       word-search pattern [flags] <pattern>\n")
+		os.Exit(2)
+	}
+
+	subcommands[os.Args[1]](os.Args[2:])
+}
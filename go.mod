@@ -1,3 +1,10 @@
 module github.com/joeyciechanowicz/letter-combinations
 
-go 1.12
+go 1.22
+
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.15
+)
+
+require golang.org/x/text v0.21.0
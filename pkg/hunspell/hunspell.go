@@ -0,0 +1,553 @@
+package hunspell
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+type Options struct {
+	// Skip stems that start with a capital letter, which is how Hunspell dictionaries mark names and places
+	DropProperNouns bool
+}
+
+type flagType int
+
+const (
+	shortFlags flagType = iota
+	longFlags
+	numericFlags
+	utf8Flags
+)
+
+/**
+A single condition element, either any letter (.), a set of letters ([abc]) or everything but a set ([^abc])
+ */
+type conditionElement struct {
+	any     bool
+	negated bool
+	letters []rune
+}
+
+func (c conditionElement) matches(letter rune) bool {
+	if c.any {
+		return true
+	}
+
+	for _, l := range c.letters {
+		if l == letter {
+			return !c.negated
+		}
+	}
+	return c.negated
+}
+
+type affixRule struct {
+	strip     []rune
+	add       []rune
+	condition []conditionElement
+}
+
+type affix struct {
+	flag         string
+	suffix       bool
+	crossProduct bool
+	rules        []affixRule
+}
+
+/**
+The parts of a .aff file needed to expand a .dic file
+ */
+type Affixes struct {
+	encoding      encoding.Encoding
+	flagType      flagType
+	aliases       [][]string
+	prefixes      map[string]*affix
+	suffixes      map[string]*affix
+	needAffix     string
+	forbiddenWord string
+}
+
+/**
+Parses a .aff file. Only the options that affect which words exist are read:
+SET, FLAG, AF, NEEDAFFIX, FORBIDDENWORD, PFX and SFX
+ */
+func ReadAffixes(r io.Reader) (*Affixes, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	affixes := &Affixes{
+		prefixes: make(map[string]*affix),
+		suffixes: make(map[string]*affix),
+	}
+
+	// SET has to be found before anything else can be decoded
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fields := strings.Fields(string(line))
+		if len(fields) == 2 && fields[0] == "SET" {
+			if affixes.encoding, err = lookupEncoding(fields[1]); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if affixes.encoding != nil {
+		if data, err = affixes.encoding.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		if err := affixes.parseLine(strings.Fields(scanner.Text())); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	return affixes, scanner.Err()
+}
+
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	if strings.EqualFold(name, "UTF-8") {
+		return nil, nil
+	}
+
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("unsupported encoding %s", name)
+	}
+	return enc, nil
+}
+
+func (a *Affixes) parseLine(fields []string) error {
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+
+	switch fields[0] {
+	case "FLAG":
+		if len(fields) < 2 {
+			return fmt.Errorf("FLAG needs a type")
+		}
+		switch fields[1] {
+		case "long":
+			a.flagType = longFlags
+		case "num":
+			a.flagType = numericFlags
+		case "UTF-8":
+			a.flagType = utf8Flags
+		default:
+			return fmt.Errorf("unknown flag type %s", fields[1])
+		}
+
+	case "AF":
+		// The first AF line is the count, the rest are aliases numbered from 1
+		if len(fields) < 2 {
+			return fmt.Errorf("AF needs flags")
+		}
+		if _, err := strconv.Atoi(fields[1]); err == nil && a.aliases == nil {
+			a.aliases = [][]string{nil}
+			return nil
+		}
+		a.aliases = append(a.aliases, a.parseFlags(fields[1]))
+
+	case "NEEDAFFIX":
+		if len(fields) > 1 {
+			a.needAffix = fields[1]
+		}
+
+	case "FORBIDDENWORD":
+		if len(fields) > 1 {
+			a.forbiddenWord = fields[1]
+		}
+
+	case "PFX", "SFX":
+		return a.parseAffix(fields)
+	}
+
+	return nil
+}
+
+/**
+Affixes start with a header (SFX flag cross_product count) followed by rules (SFX flag strip add condition)
+ */
+func (a *Affixes) parseAffix(fields []string) error {
+	if len(fields) < 4 {
+		return fmt.Errorf("%s needs at least 4 fields", fields[0])
+	}
+
+	suffix := fields[0] == "SFX"
+	affixes := a.prefixes
+	if suffix {
+		affixes = a.suffixes
+	}
+
+	flag := fields[1]
+	existing, ok := affixes[flag]
+
+	if !ok {
+		affixes[flag] = &affix{flag: flag, suffix: suffix, crossProduct: fields[2] == "Y"}
+		return nil
+	}
+
+	strip := fields[2]
+	if strip == "0" {
+		strip = ""
+	}
+
+	// Continuation flags on the affix (add/flags) aren't expanded
+	add := strings.SplitN(fields[3], "/", 2)[0]
+	if add == "0" {
+		add = ""
+	}
+
+	condition := "."
+	if len(fields) > 4 {
+		condition = fields[4]
+	}
+
+	elements, err := parseCondition(condition)
+	if err != nil {
+		return err
+	}
+
+	existing.rules = append(existing.rules, affixRule{[]rune(strip), []rune(add), elements})
+	return nil
+}
+
+func parseCondition(condition string) ([]conditionElement, error) {
+	var elements []conditionElement
+	letters := []rune(condition)
+
+	for i := 0; i < len(letters); i++ {
+		switch letters[i] {
+		case '.':
+			elements = append(elements, conditionElement{any: true})
+
+		case '[':
+			end := i + 1
+			for end < len(letters) && letters[end] != ']' {
+				end++
+			}
+			if end == len(letters) {
+				return nil, fmt.Errorf("unterminated condition %s", condition)
+			}
+
+			element := conditionElement{}
+			set := letters[i+1 : end]
+			if len(set) > 0 && set[0] == '^' {
+				element.negated = true
+				set = set[1:]
+			}
+			element.letters = append([]rune{}, set...)
+
+			elements = append(elements, element)
+			i = end
+
+		default:
+			elements = append(elements, conditionElement{letters: []rune{letters[i]}})
+		}
+	}
+
+	return elements, nil
+}
+
+func (a *Affixes) parseFlags(flags string) []string {
+	var parsed []string
+
+	switch a.flagType {
+	case longFlags:
+		letters := []rune(flags)
+		for i := 0; i+1 < len(letters); i += 2 {
+			parsed = append(parsed, string(letters[i:i+2]))
+		}
+
+	case numericFlags:
+		for _, flag := range strings.Split(flags, ",") {
+			if flag != "" {
+				parsed = append(parsed, flag)
+			}
+		}
+
+	case utf8Flags:
+		for _, flag := range flags {
+			parsed = append(parsed, string(flag))
+		}
+
+	default:
+		// Short flags are single bytes, which for 8-bit dictionaries have already been decoded to a rune
+		for _, flag := range flags {
+			parsed = append(parsed, string(flag))
+		}
+	}
+
+	return parsed
+}
+
+/**
+Flags on a .dic line are either literal or, when the .aff has AF aliases, a number referring to one
+ */
+func (a *Affixes) dictionaryFlags(flags string) []string {
+	if len(a.aliases) > 0 {
+		if index, err := strconv.Atoi(flags); err == nil && index > 0 && index < len(a.aliases) {
+			return a.aliases[index]
+		}
+	}
+	return a.parseFlags(flags)
+}
+
+func (rule affixRule) apply(word []rune, suffix bool) ([]rune, bool) {
+	if len(word) < len(rule.condition) || len(word) < len(rule.strip) {
+		return nil, false
+	}
+
+	if suffix {
+		offset := len(word) - len(rule.condition)
+		for i, element := range rule.condition {
+			if !element.matches(word[offset+i]) {
+				return nil, false
+			}
+		}
+
+		stem := word[:len(word)-len(rule.strip)]
+		if string(word[len(stem):]) != string(rule.strip) {
+			return nil, false
+		}
+
+		return append(append([]rune{}, stem...), rule.add...), true
+	}
+
+	for i, element := range rule.condition {
+		if !element.matches(word[i]) {
+			return nil, false
+		}
+	}
+
+	if string(word[:len(rule.strip)]) != string(rule.strip) {
+		return nil, false
+	}
+
+	return append(append([]rune{}, rule.add...), word[len(rule.strip):]...), true
+}
+
+/**
+Calls cb with the stem and every word its flags produce. Suffixes are applied first, then prefixes, with
+prefixes also applied to suffixed words when both affixes allow cross products
+ */
+func (a *Affixes) expandStem(stem string, flags []string, cb func(string) error) error {
+	word := []rune(stem)
+	var prefixes, suffixes []*affix
+
+	needAffix := false
+	for _, flag := range flags {
+		if flag == a.forbiddenWord && flag != "" {
+			return nil
+		}
+		if flag == a.needAffix && flag != "" {
+			needAffix = true
+		}
+		if prefix, ok := a.prefixes[flag]; ok {
+			prefixes = append(prefixes, prefix)
+		}
+		if suffix, ok := a.suffixes[flag]; ok {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+
+	if !needAffix {
+		if err := cb(stem); err != nil {
+			return err
+		}
+	}
+
+	for _, suffix := range suffixes {
+		for _, rule := range suffix.rules {
+			suffixed, ok := rule.apply(word, true)
+			if !ok {
+				continue
+			}
+
+			if err := cb(string(suffixed)); err != nil {
+				return err
+			}
+
+			if !suffix.crossProduct {
+				continue
+			}
+
+			for _, prefix := range prefixes {
+				if !prefix.crossProduct {
+					continue
+				}
+
+				if err := a.applyAffix(prefix, suffixed, cb); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, prefix := range prefixes {
+		if err := a.applyAffix(prefix, word, cb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Affixes) applyAffix(affix *affix, word []rune, cb func(string) error) error {
+	for _, rule := range affix.rules {
+		if affixed, ok := rule.apply(word, affix.suffix); ok {
+			if err := cb(string(affixed)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+Reads a .dic file and calls cb with every unique word it expands to
+ */
+func (a *Affixes) Expand(ctx context.Context, dic io.Reader, opts Options, cb func(string) error) error {
+	if a.encoding != nil {
+		dic = transform.NewReader(dic, a.encoding.NewDecoder())
+	}
+
+	scanner := bufio.NewScanner(dic)
+	seen := make(map[string]bool)
+	lineNumber := 0
+
+	emit := func(word string) error {
+		if seen[word] {
+			return nil
+		}
+		seen[word] = true
+		return cb(word)
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+
+		// The first line is the approximate number of words
+		if lineNumber == 1 {
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+
+		if line == "" {
+			continue
+		}
+
+		stem, flags := splitDictionaryLine(line)
+		if stem == "" {
+			continue
+		}
+
+		if first, _ := utf8.DecodeRuneInString(stem); opts.DropProperNouns && unicode.IsUpper(first) {
+			continue
+		}
+
+		if err := a.expandStem(stem, a.dictionaryFlags(flags), emit); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+/**
+Splits "word/flags morphology" into the word and flags. Slashes in the word are escaped as \/
+ */
+func splitDictionaryLine(line string) (string, string) {
+	if end := strings.IndexAny(line, " \t"); end >= 0 {
+		line = line[:end]
+	}
+
+	var stem strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '/':
+			stem.WriteByte('/')
+			i++
+		case line[i] == '/':
+			return stem.String(), line[i+1:]
+		default:
+			stem.WriteByte(line[i])
+		}
+	}
+
+	return stem.String(), ""
+}
+
+/**
+Expands dicFile using affFile and returns the words as a newline separated stream,
+ready for trie.Read
+ */
+func Open(ctx context.Context, dicFile string, affFile string, opts Options) (io.ReadCloser, error) {
+	affHandle, err := os.Open(affFile)
+	if err != nil {
+		return nil, err
+	}
+	defer affHandle.Close()
+
+	affixes, err := ReadAffixes(affHandle)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", affFile, err)
+	}
+
+	dicHandle, err := os.Open(dicFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		defer dicHandle.Close()
+
+		writer := bufio.NewWriter(pipeWriter)
+		err := affixes.Expand(ctx, dicHandle, opts, func(word string) error {
+			_, err := writer.WriteString(word + "\n")
+			return err
+		})
+		if err == nil {
+			err = writer.Flush()
+		} else {
+			err = fmt.Errorf("%s: %w", dicFile, err)
+		}
+
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader, nil
+}
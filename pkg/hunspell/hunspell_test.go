@@ -0,0 +1,65 @@
+package hunspell
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func expand(t *testing.T, opts Options) []string {
+	stream, err := Open(context.Background(), "testdata/en.dic", "testdata/en.aff", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := strings.Fields(string(data))
+	sort.Strings(words)
+	return words
+}
+
+func TestExpand(t *testing.T) {
+	got := strings.Join(expand(t, Options{}), ",")
+	want := "London,Londons,Paris,acked,cat,cats,ladies,lady,tie,tied,untie,untied"
+
+	if got != want {
+		t.Errorf("Words were incorrect, got: %s, want: %s.", got, want)
+	}
+}
+
+func TestExpandDropProperNouns(t *testing.T) {
+	got := strings.Join(expand(t, Options{DropProperNouns: true}), ",")
+	want := "acked,cat,cats,ladies,lady,tie,tied,untie,untied"
+
+	if got != want {
+		t.Errorf("Words were incorrect, got: %s, want: %s.", got, want)
+	}
+}
+
+func TestFlagTypes(t *testing.T) {
+	aff := "FLAG long\nSFX Aa Y 1\nSFX Aa 0 s .\nAF 1\nAF Aa\n"
+	affixes, err := ReadAffixes(strings.NewReader(aff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var words []string
+	err = affixes.Expand(context.Background(), strings.NewReader("2\ndog/Aa\ncat/1\n"), Options{}, func(word string) error {
+		words = append(words, word)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(words, ",") != "dog,dogs,cat,cats" {
+		t.Errorf("Words were incorrect, got: %v, want: %v.", words, "dog,dogs,cat,cats")
+	}
+}
@@ -0,0 +1,236 @@
+package normalise
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const EnglishAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+/**
+A single step of a Pipeline. Apply returns the transformed word, or a non-empty reason if the word should be rejected.
+Params is whatever the stage was made with, so two stages with the same name can be told apart
+ */
+type Stage struct {
+	Name   string
+	Apply  func(word string) (string, string)
+	Params string
+}
+
+/**
+Counts how many words a stage rejected for a given reason
+ */
+type Rejection struct {
+	Stage  string
+	Reason string
+	Count  int
+}
+
+/**
+Returned from Pipeline.Normalise when a word is rejected
+ */
+type RejectedError struct {
+	Word   string
+	Stage  string
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%q rejected by %s: %s", e.Word, e.Stage, e.Reason)
+}
+
+/**
+Runs words through a list of stages before they are inserted into a trie, keeping count of what was rejected and why.
+By default rejected words are skipped, when Strict is set they are returned as errors instead
+ */
+type Pipeline struct {
+	Stages []Stage
+	Strict bool
+
+	rejections map[Rejection]int
+}
+
+func New(stages ...Stage) *Pipeline {
+	return &Pipeline{
+		Stages:     stages,
+		rejections: make(map[Rejection]int),
+	}
+}
+
+/**
+Pipeline for a-z word lists
+ */
+func English() *Pipeline {
+	return New(TrimSpace, RejectBlank, Lowercase, FoldDiacritics, OnlyLetters(EnglishAlphabet))
+}
+
+/**
+Pipeline keeping any unicode letter
+ */
+func Unicode() *Pipeline {
+	return New(TrimSpace, RejectBlank, Lowercase, RejectNonLetters)
+}
+
+var TrimSpace = Stage{"trim-space", func(word string) (string, string) {
+	return strings.TrimSpace(word), ""
+}, ""}
+
+var RejectBlank = Stage{"reject-blank", func(word string) (string, string) {
+	if word == "" {
+		return word, "blank line"
+	}
+	return word, ""
+}, ""}
+
+var Lowercase = Stage{"lowercase", func(word string) (string, string) {
+	return strings.ToLower(word), ""
+}, ""}
+
+/**
+Removes accents, i.e. café becomes cafe
+ */
+var FoldDiacritics = Stage{"fold-diacritics", func(word string) (string, string) {
+	if isASCII(word) {
+		return word, ""
+	}
+
+	// Chained transformers hold state so can't be shared between goroutines
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(folder, word)
+	if err != nil {
+		return word, err.Error()
+	}
+	return folded, ""
+}, ""}
+
+func isASCII(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+var RejectNonLetters = Stage{"reject-non-letters", func(word string) (string, string) {
+	for _, letter := range word {
+		if !unicode.IsLetter(letter) {
+			return word, fmt.Sprintf("contains %q", letter)
+		}
+	}
+	return word, ""
+}, ""}
+
+/**
+Rejects any word containing a letter outside of alphabet
+ */
+func OnlyLetters(alphabet string) Stage {
+	return Stage{"only-letters", func(word string) (string, string) {
+		for _, letter := range word {
+			if !strings.ContainsRune(alphabet, letter) {
+				return word, fmt.Sprintf("contains %q", letter)
+			}
+		}
+		return word, ""
+	}, alphabet}
+}
+
+/**
+Runs word through every stage, returning a *RejectedError from the first stage that rejects it
+ */
+func (p *Pipeline) Normalise(word string) (string, error) {
+	original := word
+
+	for _, stage := range p.Stages {
+		var reason string
+		word, reason = stage.Apply(word)
+
+		if reason != "" {
+			if p.rejections == nil {
+				p.rejections = make(map[Rejection]int)
+			}
+			p.rejections[Rejection{Stage: stage.Name, Reason: reason}]++
+			return "", &RejectedError{original, stage.Name, reason}
+		}
+	}
+
+	return word, nil
+}
+
+/**
+Wraps a reader callback so cb only receives normalised words.
+Rejected words are skipped unless the pipeline is Strict
+ */
+func (p *Pipeline) Filter(cb func(string) error) func(string) error {
+	return func(word string) error {
+		normalised, err := p.Normalise(word)
+		if err != nil {
+			if p.Strict {
+				return err
+			}
+			return nil
+		}
+
+		return cb(normalised)
+	}
+}
+
+/**
+Adds rejections counted elsewhere, i.e. by the pipeline a cached trie was built with
+ */
+func (p *Pipeline) Record(rejections []Rejection) {
+	if p.rejections == nil {
+		p.rejections = make(map[Rejection]int)
+	}
+	for _, rejection := range rejections {
+		p.rejections[Rejection{Stage: rejection.Stage, Reason: rejection.Reason}] += rejection.Count
+	}
+}
+
+/**
+Total number of words rejected so far
+ */
+func (p *Pipeline) Rejected() int {
+	total := 0
+	for _, count := range p.rejections {
+		total += count
+	}
+	return total
+}
+
+/**
+Every stage and reason that rejected a word, in stage order then by most common reason
+ */
+func (p *Pipeline) Rejections() []Rejection {
+	stageOrder := make(map[string]int)
+	for i, stage := range p.Stages {
+		stageOrder[stage.Name] = i
+	}
+
+	var rejections []Rejection
+	for rejection, count := range p.rejections {
+		rejection.Count = count
+		rejections = append(rejections, rejection)
+	}
+
+	sort.Slice(rejections, func(i, j int) bool {
+		a, b := rejections[i], rejections[j]
+		if stageOrder[a.Stage] != stageOrder[b.Stage] {
+			return stageOrder[a.Stage] < stageOrder[b.Stage]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+
+	return rejections
+}
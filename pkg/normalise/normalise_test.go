@@ -0,0 +1,92 @@
+package normalise
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnglish(t *testing.T) {
+	pipeline := English()
+
+	cases := map[string]string{
+		"Hello\r":  "hello",
+		"café":     "cafe",
+		"  Naïve ": "naive",
+	}
+
+	for word, want := range cases {
+		got, err := pipeline.Normalise(word)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", word, err)
+		}
+		if got != want {
+			t.Errorf("Normalised word was incorrect, got: %q, want: %q.", got, want)
+		}
+	}
+}
+
+func TestEnglishRejections(t *testing.T) {
+	pipeline := English()
+
+	for _, word := range []string{"", "   ", "don't", "well-known", "x-ray", "日本"} {
+		_, err := pipeline.Normalise(word)
+
+		var rejected *RejectedError
+		if !errors.As(err, &rejected) {
+			t.Errorf("Expected %q to be rejected, got: %v", word, err)
+		}
+	}
+
+	if pipeline.Rejected() != 6 {
+		t.Errorf("Rejected count was incorrect, got: %d, want: %d.", pipeline.Rejected(), 6)
+	}
+
+	want := []Rejection{
+		{"reject-blank", "blank line", 2},
+		{"only-letters", "contains '-'", 2},
+		{"only-letters", `contains '\''`, 1},
+		{"only-letters", "contains '日'", 1},
+	}
+
+	got := pipeline.Rejections()
+	if len(got) != len(want) {
+		t.Fatalf("Rejections were incorrect, got: %v, want: %v.", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Rejection %d was incorrect, got: %v, want: %v.", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	built := English()
+	for _, word := range []string{"", "don't", "x-ray"} {
+		built.Normalise(word)
+	}
+
+	pipeline := English()
+	pipeline.Record(built.Rejections())
+
+	if pipeline.Rejected() != 3 {
+		t.Errorf("Rejected count was incorrect, got: %d, want: %d.", pipeline.Rejected(), 3)
+	}
+	if got, want := pipeline.Rejections(), built.Rejections(); len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("Rejections were incorrect, got: %v, want: %v.", got, want)
+	}
+}
+
+func TestFilterStrict(t *testing.T) {
+	pipeline := English()
+	pipeline.Strict = true
+
+	called := false
+	err := pipeline.Filter(func(string) error {
+		called = true
+		return nil
+	})("don't")
+
+	if err == nil || called {
+		t.Errorf("Expected strict pipeline to return an error without calling the callback")
+	}
+}
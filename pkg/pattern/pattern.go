@@ -0,0 +1,321 @@
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+/**
+Longest word length tracked exactly in a node's lengths, longer words all share the last bit
+ */
+const maxTrackedLength = 63
+
+/**
+A trie of words in their written order, unlike trie.Node which uses sorted letters.
+lengths has bit n set when a word of n letters ends at or below the node, so patterns can skip branches that only
+have words of the wrong length
+ */
+type node struct {
+	children map[int]*node
+	words    []*trie.WordDetails
+	lengths  uint64
+}
+
+/**
+Positional index over a word list for crossword style pattern queries
+ */
+type Index struct {
+	alphabet trie.Alphabet
+	root     node
+}
+
+func lengthBit(length int) uint64 {
+	if length > maxTrackedLength {
+		length = maxTrackedLength
+	}
+	return 1 << uint(length)
+}
+
+/**
+Indexes words, usually the words returned alongside a trie by trie.Open. The words must outlive the index
+ */
+func New(alphabet trie.Alphabet, words []trie.WordDetails) (*Index, error) {
+	index := &Index{alphabet: alphabet, root: node{children: make(map[int]*node)}}
+
+	for i := range words {
+		letters := []rune(words[i].Word)
+		bit := lengthBit(len(letters))
+
+		head := &index.root
+		head.lengths |= bit
+
+		for _, letter := range letters {
+			position := alphabet.Index(letter)
+			if position < 0 {
+				return nil, fmt.Errorf("%q contains %q which is not in the alphabet", words[i].Word, letter)
+			}
+
+			child, ok := head.children[position]
+			if !ok {
+				child = &node{children: make(map[int]*node)}
+				head.children[position] = child
+			}
+
+			head = child
+			head.lengths |= bit
+		}
+
+		head.words = append(head.words, &words[i])
+	}
+
+	return index, nil
+}
+
+/**
+One position of a pattern, either a single letter from letters (any letter when any is set) or, for a star, zero or
+more letters
+ */
+type token struct {
+	star    bool
+	any     bool
+	letters []int
+}
+
+func (t token) accepts(letter int) bool {
+	if t.any {
+		return true
+	}
+	for _, accepted := range t.letters {
+		if accepted == letter {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+A parsed pattern. Letters match themselves, ? or . match any one letter, * matches any number of letters including
+none and [abc] matches one of the letters inside the brackets
+ */
+type Pattern struct {
+	tokens []token
+
+	// fixed[i] is how many letters tokens i onwards need at least, star[i] whether any of them is a star
+	fixed []int
+	star  []bool
+}
+
+func Parse(alphabet trie.Alphabet, pattern string) (*Pattern, error) {
+	p := &Pattern{}
+	letters := []rune(strings.ToLower(pattern))
+
+	for i := 0; i < len(letters); i++ {
+		switch letters[i] {
+		case '*':
+			// Consecutive stars are the same as one
+			if len(p.tokens) == 0 || !p.tokens[len(p.tokens)-1].star {
+				p.tokens = append(p.tokens, token{star: true})
+			}
+		case '?', '.':
+			p.tokens = append(p.tokens, token{any: true})
+		case '[':
+			end := i + 1
+			for end < len(letters) && letters[end] != ']' {
+				end++
+			}
+			if end == len(letters) || end == i+1 {
+				return nil, fmt.Errorf("%q has an unclosed or empty [", pattern)
+			}
+
+			var class token
+			for _, letter := range letters[i+1 : end] {
+				position := alphabet.Index(letter)
+				if position < 0 {
+					return nil, fmt.Errorf("%q contains %q which is not in the alphabet", pattern, letter)
+				}
+				class.letters = append(class.letters, position)
+			}
+			p.tokens = append(p.tokens, class)
+			i = end
+		default:
+			position := alphabet.Index(letters[i])
+			if position < 0 {
+				return nil, fmt.Errorf("%q contains %q which is not in the alphabet", pattern, letters[i])
+			}
+			p.tokens = append(p.tokens, token{letters: []int{position}})
+		}
+	}
+
+	p.fixed = make([]int, len(p.tokens)+1)
+	p.star = make([]bool, len(p.tokens)+1)
+	for i := len(p.tokens) - 1; i >= 0; i-- {
+		p.fixed[i] = p.fixed[i+1]
+		p.star[i] = p.star[i+1] || p.tokens[i].star
+		if !p.tokens[i].star {
+			p.fixed[i]++
+		}
+	}
+
+	return p, nil
+}
+
+/**
+Which words to find in Index.Search
+ */
+type Query struct {
+	Pattern *Pattern
+
+	// When set words can only use these letters, at most this many times each
+	Letters []trie.LetterCount
+
+	// Words must use each of these letters at least this many times
+	Required []trie.LetterCount
+
+	// Length in letters, 0 for no limit
+	MinLength int
+	MaxLength int
+
+	// Only return the first this many words alphabetically, 0 for no limit
+	Limit int
+}
+
+type search struct {
+	query     Query
+	available []int
+	limited   bool
+	visited   map[visit]bool
+	words     []*trie.WordDetails
+}
+
+type visit struct {
+	node  *node
+	token int
+}
+
+/**
+Every word matching the query, in alphabetical order. The whole pattern is always walked, so Limit only trims the
+result
+ */
+func (index *Index) Search(query Query) []*trie.WordDetails {
+	s := &search{
+		query:   query,
+		limited: len(query.Letters) > 0,
+		visited: make(map[visit]bool),
+	}
+
+	if s.limited {
+		s.available = make([]int, index.alphabet.Size())
+		for _, letterCount := range query.Letters {
+			s.available[letterCount.Letter] += int(letterCount.Count)
+		}
+	}
+
+	s.walk(&index.root, 0, 0)
+
+	sort.Slice(s.words, func(i, j int) bool {
+		return s.words[i].Word < s.words[j].Word
+	})
+	if query.Limit > 0 && len(s.words) > query.Limit {
+		s.words = s.words[:query.Limit]
+	}
+
+	return s.words
+}
+
+/**
+The lengths a word below a node at depth can have and still match from token onwards
+ */
+func (s *search) lengthMask(depth int, position int) uint64 {
+	shortest := depth + s.query.Pattern.fixed[position]
+	if shortest < s.query.MinLength {
+		shortest = s.query.MinLength
+	}
+
+	longest := shortest
+	if s.query.Pattern.star[position] {
+		longest = maxTrackedLength
+	}
+	if s.query.MaxLength > 0 && s.query.MaxLength < longest {
+		longest = s.query.MaxLength
+	}
+
+	var mask uint64
+	for length := shortest; length <= longest && length <= maxTrackedLength; length++ {
+		mask |= lengthBit(length)
+	}
+	return mask
+}
+
+func (s *search) matches(word *trie.WordDetails, length int) bool {
+	if length < s.query.MinLength || (s.query.MaxLength > 0 && length > s.query.MaxLength) {
+		return false
+	}
+	if s.limited && !trie.IsSubset(word.SortedLetterCounts, s.query.Letters) {
+		return false
+	}
+	return trie.IsSubset(s.query.Required, word.SortedLetterCounts)
+}
+
+/**
+Walks the pattern and the trie together. A star either matches nothing and moves on to the next token, or takes
+a letter and stays put, so the same node and token can be reached more than once and visited stops repeats
+ */
+func (s *search) walk(head *node, position int, depth int) {
+	if head.lengths&s.lengthMask(depth, position) == 0 {
+		return
+	}
+
+	key := visit{head, position}
+	if s.visited[key] {
+		return
+	}
+	s.visited[key] = true
+
+	tokens := s.query.Pattern.tokens
+
+	if position == len(tokens) {
+		for _, word := range head.words {
+			if s.matches(word, depth) {
+				s.words = append(s.words, word)
+			}
+		}
+		return
+	}
+
+	current := tokens[position]
+	if current.star {
+		s.walk(head, position+1, depth)
+	}
+
+	next := position + 1
+	if current.star {
+		next = position
+	}
+
+	take := func(letter int, child *node) {
+		if s.limited {
+			if s.available[letter] == 0 {
+				return
+			}
+			s.available[letter]--
+			defer func() { s.available[letter]++ }()
+		}
+		s.walk(child, next, depth+1)
+	}
+
+	if current.star || current.any {
+		for letter, child := range head.children {
+			take(letter, child)
+		}
+		return
+	}
+
+	for _, letter := range current.letters {
+		if child, ok := head.children[letter]; ok {
+			take(letter, child)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package pattern
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/trie"
+)
+
+func TestSearch(t *testing.T) {
+	_, words, err := trie.Read(context.Background(), strings.NewReader("cattle\ncastle\ncuttle\nsing\nstring\nsinging\nsling\nsings\nkettle\nsettle\n"), trie.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := New(trie.English, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	letters := trie.MustNewWordDetails(trie.English, "cattlesu").SortedLetterCounts
+	required := trie.MustNewWordDetails(trie.English, "s").SortedLetterCounts
+
+	cases := []struct {
+		pattern string
+		query   Query
+		want    string
+	}{
+		{"c?t??e", Query{}, "cattle cuttle"},
+		{"s*ing", Query{}, "sing singing sling string"},
+		{"*ing*", Query{}, "sing singing sings sling string"},
+		{"*", Query{MinLength: 7}, "singing"},
+		{"[ck]?tt*", Query{}, "cattle cuttle kettle"},
+		{"??tt??", Query{Letters: letters}, "cattle cuttle"},
+		{"*tle", Query{Letters: letters, Required: required}, "castle"},
+		{"*tle", Query{Required: required}, "castle settle"},
+		{"s*", Query{Limit: 2}, "settle sing"},
+		{"x*", Query{}, ""},
+	}
+
+	for _, c := range cases {
+		p, err := Parse(trie.English, c.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.query.Pattern = p
+
+		var got []string
+		for _, word := range index.Search(c.query) {
+			got = append(got, word.Word)
+		}
+
+		if strings.Join(got, " ") != c.want {
+			t.Errorf("%s was incorrect, got: %v, want: %s.", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, pattern := range []string{"c[at", "c[]t", "c1t"} {
+		if _, err := Parse(trie.English, pattern); err == nil {
+			t.Errorf("Expected an error for %s", pattern)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+/**
+bzip2 streams start with BZh and a block size from 1 to 9, checking the block size too keeps word lists that
+happen to start with BZh from being read as bzip2
+ */
+func isBzip2(magic []byte) bool {
+	return len(magic) > len(bzip2Magic) && bytes.HasPrefix(magic, bzip2Magic) &&
+		magic[len(bzip2Magic)] >= '1' && magic[len(bzip2Magic)] <= '9'
+}
+
+/**
+Detects the compression format of r from its magic bytes and returns a reader of the decompressed stream.
+gzip, bzip2, zstd and xz are supported, anything else is passed through as-is.
+The returned reader must be closed to release any decoder resources, it does not close r
+ */
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+
+	// Peek returns fewer bytes and an error for short input, which just means no format matched
+	magic, _ := buffered.Peek(len(xzMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+
+	case isBzip2(magic):
+		return io.NopCloser(bzip2.NewReader(buffered)), nil
+
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+
+	case bytes.HasPrefix(magic, xzMagic):
+		decoder, err := xz.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(decoder), nil
+	}
+
+	return io.NopCloser(buffered), nil
+}
@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const wordList = "cat\ndog\nemu\n"
+
+func compress(t *testing.T, newWriter func(io.Writer) (io.WriteCloser, error)) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	w, err := newWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, wordList); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestReadCompressed(t *testing.T) {
+	formats := map[string]func(io.Writer) (io.WriteCloser, error){
+		"plain": func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+		"gzip":  func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+		"zstd":  func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) },
+		"xz":    func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) },
+	}
+
+	for name, newWriter := range formats {
+		t.Run(name, func(t *testing.T) {
+			var lines []string
+			err := Read(context.Background(), compress(t, newWriter), func(line string) error {
+				lines = append(lines, line)
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if strings.Join(lines, "\n")+"\n" != wordList {
+				t.Errorf("Lines were incorrect, got: %q, want: %q.", lines, wordList)
+			}
+		})
+	}
+}
+
+/**
+The standard library can only decompress bzip2, so its test reads a file compressed with the bzip2 command
+ */
+func TestReadBzip2(t *testing.T) {
+	var lines []string
+	err := ReadFileContext(context.Background(), "testdata/words.txt.bz2", func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(lines, "\n")+"\n" != wordList {
+		t.Errorf("Lines were incorrect, got: %q, want: %q.", lines, wordList)
+	}
+}
+
+func TestReadPlainStartingWithBZh(t *testing.T) {
+	var lines []string
+	err := Read(context.Background(), strings.NewReader("BZhang\ncat\n"), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(lines, ",") != "BZhang,cat" {
+		t.Errorf("Lines were incorrect, got: %q, want: %q.", lines, "BZhang,cat")
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,66 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/**
+A single line of a dictionary. Plain word lists only have a Word, TSV dictionaries are
+word<TAB>frequency[<TAB>tags] where tags are comma separated
+ */
+type Entry struct {
+	Word      string
+	Frequency float64
+	Tags      []string
+}
+
+func ParseEntry(line string) (Entry, error) {
+	fields := strings.Split(strings.TrimRight(line, "\r\n"), "\t")
+	entry := Entry{Word: fields[0]}
+
+	if len(fields) > 3 {
+		return entry, fmt.Errorf("expected at most 3 tab separated fields, got %d", len(fields))
+	}
+
+	if len(fields) > 1 && fields[1] != "" {
+		frequency, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return entry, fmt.Errorf("invalid frequency for %q: %w", entry.Word, err)
+		}
+		entry.Frequency = frequency
+	}
+
+	if len(fields) > 2 {
+		for _, tag := range strings.Split(fields[2], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+/**
+Reads the words of a plain or TSV dictionary into a set
+ */
+func ReadWordSet(ctx context.Context, filename string) (map[string]bool, error) {
+	set := make(map[string]bool)
+
+	err := ReadFileContext(ctx, filename, func(line string) error {
+		entry, err := ParseEntry(line)
+		if err != nil {
+			return err
+		}
+
+		if word := strings.TrimSpace(entry.Word); word != "" {
+			set[word] = true
+		}
+		return nil
+	})
+
+	return set, err
+}
@@ -2,32 +2,97 @@ package reader
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
 	"log"
 	"os"
 )
 
-func ReadFile(filename string, cb func(string)) {
-	fileHandle, err := os.Open(filename)
+/**
+Wraps an error that occurred while reading a word list with the line it happened on
+ */
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+/**
+Calls cb with every line of r, decompressing it first if needed (see Decompress). Reading stops at the first
+error returned by cb, a scan error or when ctx is cancelled. Errors from a specific line are returned as a *LineError
+ */
+func Read(ctx context.Context, r io.Reader, cb func(string) error) (err error) {
+	decompressed, err := Decompress(r)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer closeIO(fileHandle)
+	defer func() {
+		if closeErr := decompressed.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	scanner := bufio.NewScanner(decompressed)
+	line := 0
 
-	scanner := bufio.NewScanner(fileHandle)
 	for scanner.Scan() {
-		cb(scanner.Text())
+		line++
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := cb(scanner.Text()); err != nil {
+			return &LineError{line, err}
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+		return &LineError{line + 1, err}
+	}
+
+	return nil
+}
+
+/**
+Opens filename and calls cb with every line, see Read
+ */
+func ReadFileContext(ctx context.Context, filename string, cb func(string) error) (err error) {
+	fileHandle, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := fileHandle.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if err := Read(ctx, fileHandle, cb); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
 	}
+
+	return nil
 }
 
+/**
+Calls cb with every line of filename, exiting the program on any error
+ */
+func ReadFile(filename string, cb func(string)) {
+	err := ReadFileContext(context.Background(), filename, func(line string) error {
+		cb(line)
+		return nil
+	})
 
-func closeIO(c io.Closer) {
-	err := c.Close()
 	if err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,94 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	var lines []string
+	err := Read(context.Background(), strings.NewReader("cat\ndog\nemu\n"), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(lines, ",") != "cat,dog,emu" {
+		t.Errorf("Lines were incorrect, got: %v, want: %v.", lines, []string{"cat", "dog", "emu"})
+	}
+}
+
+func TestReadReturnsLineNumber(t *testing.T) {
+	badWord := errors.New("bad word")
+
+	err := Read(context.Background(), strings.NewReader("cat\ndog\nemu\n"), func(line string) error {
+		if line == "dog" {
+			return badWord
+		}
+		return nil
+	})
+
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) {
+		t.Fatalf("Error was not a *LineError, got: %v", err)
+	}
+
+	if lineErr.Line != 2 {
+		t.Errorf("Line was incorrect, got: %d, want: %d.", lineErr.Line, 2)
+	}
+
+	if !errors.Is(err, badWord) {
+		t.Errorf("Error did not wrap the callback error, got: %v", err)
+	}
+}
+
+func TestReadCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Read(ctx, strings.NewReader("cat\n"), func(line string) error {
+		t.Errorf("Callback called after cancellation with %s", line)
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Error was incorrect, got: %v, want: %v.", err, context.Canceled)
+	}
+}
+
+func TestReadFileContextMissingFile(t *testing.T) {
+	err := ReadFileContext(context.Background(), "./does-not-exist.txt", func(string) error { return nil })
+
+	if err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	cases := map[string]Entry{
+		"aiel":                    {Word: "aiel"},
+		"plainer\t1520":           {Word: "plainer", Frequency: 1520},
+		"plain\t0.25\tcommon,adj": {Word: "plain", Frequency: 0.25, Tags: []string{"common", "adj"}},
+	}
+
+	for line, want := range cases {
+		got, err := ParseEntry(line)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", line, err)
+			continue
+		}
+
+		if got.Word != want.Word || got.Frequency != want.Frequency || strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") {
+			t.Errorf("Entry was incorrect, got: %+v, want: %+v.", got, want)
+		}
+	}
+
+	if _, err := ParseEntry("plain\tlots"); err == nil {
+		t.Errorf("Expected an error for a non-numeric frequency")
+	}
+}
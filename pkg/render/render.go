@@ -0,0 +1,65 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Fewest letters Wheel can draw, the centre and one either side of it
+const MinWheelSize = 3
+
+func centre(text string, width int) string {
+	padding := width - utf8.RuneCountInString(text)
+	if padding <= 0 {
+		return text
+	}
+	return strings.Repeat(" ", padding/2) + text + strings.Repeat(" ", padding-padding/2)
+}
+
+/**
+Draws the outer letters clockwise from the top left around the centre letter. The ring has a letter either side of
+the centre and splits the rest between the top and bottom rows, so needs at least 2 outer letters. For 9 letters
+
+	┏━━━━━━━━━━━┓
+	┃ a   b   c ┃
+	┃   ┏━━━┓   ┃
+	┃ h ┃ x ┃ d ┃
+	┃   ┗━━━┛   ┃
+	┃ g   f   e ┃
+	┗━━━━━━━━━━━┛
+ */
+func Wheel(centreLetter string, outer []string, note string) string {
+	rest := len(outer) - 2
+	topCount := rest - rest/2
+
+	top := outer[:topCount]
+	right := outer[topCount]
+	bottom := outer[topCount+1 : len(outer)-1]
+	left := outer[len(outer)-1]
+
+	// Bottom row is read right to left to keep going clockwise
+	reversed := make([]string, len(bottom))
+	for i, letter := range bottom {
+		reversed[len(bottom)-1-i] = letter
+	}
+
+	width := 11
+	if rowWidth := 4*max(len(top), len(bottom)) - 1; rowWidth > width {
+		width = rowWidth
+	}
+
+	var output strings.Builder
+	output.WriteString("┏" + strings.Repeat("━", width) + "┓\n")
+	output.WriteString("┃" + centre(strings.Join(top, "   "), width) + "┃\n")
+	output.WriteString("┃" + centre("┏━━━┓", width) + "┃\n")
+	output.WriteString("┃ " + left + centre("┃ "+centreLetter+" ┃", width-4) + right + " ┃")
+	if note != "" {
+		output.WriteString("  " + note)
+	}
+	output.WriteString("\n")
+	output.WriteString("┃" + centre("┗━━━┛", width) + "┃\n")
+	output.WriteString("┃" + centre(strings.Join(reversed, "   "), width) + "┃\n")
+	output.WriteString("┗" + strings.Repeat("━", width) + "┛\n")
+
+	return output.String()
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWheel(t *testing.T) {
+	nine := Wheel("x", strings.Split("abcdefgh", ""), "Found 1")
+	want := `┏━━━━━━━━━━━┓
+┃ a   b   c ┃
+┃   ┏━━━┓   ┃
+┃ h ┃ x ┃ d ┃  Found 1
+┃   ┗━━━┛   ┃
+┃ g   f   e ┃
+┗━━━━━━━━━━━┛
+`
+	if nine != want {
+		t.Errorf("Render was incorrect, got:\n%s\nwant:\n%s", nine, want)
+	}
+
+	for size := MinWheelSize; size <= 16; size++ {
+		outer := strings.Split("abcdefghijklmnopqrstuvwxyz"[:size-1], "")
+		rendered := Wheel("x", outer, "")
+
+		for _, letter := range outer {
+			if !strings.Contains(rendered, letter) {
+				t.Errorf("Size %d is missing %s:\n%s", size, letter, rendered)
+			}
+		}
+
+		lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+		for _, line := range lines {
+			if utf8.RuneCountInString(line) != utf8.RuneCountInString(lines[0]) {
+				t.Errorf("Size %d has uneven lines:\n%s", size, rendered)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package trie
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+/**
+Maps the letters of a word to dense indexes, which are what the trie is keyed by
+ */
+type Alphabet interface {
+	// Index of letter, or -1 if it isn't in the alphabet
+	Index(letter rune) int
+	Letter(index int) rune
+	Size() int
+}
+
+/**
+The letters a-z, indexed 0-25
+ */
+var English Alphabet = englishAlphabet{}
+
+/**
+Every unicode code point, indexed by its value
+ */
+var Unicode Alphabet = unicodeAlphabet{}
+
+type englishAlphabet struct{}
+
+func (englishAlphabet) Index(letter rune) int {
+	if letter < 'a' || letter > 'z' {
+		return -1
+	}
+	return int(letter - 'a')
+}
+
+func (englishAlphabet) Letter(index int) rune {
+	return rune('a' + index)
+}
+
+func (englishAlphabet) Size() int {
+	return 26
+}
+
+type unicodeAlphabet struct{}
+
+func (unicodeAlphabet) Index(letter rune) int {
+	if !utf8.ValidRune(letter) {
+		return -1
+	}
+	return int(letter)
+}
+
+func (unicodeAlphabet) Letter(index int) rune {
+	return rune(index)
+}
+
+func (unicodeAlphabet) Size() int {
+	return unicode.MaxRune + 1
+}
+
+/**
+An alphabet of the given letters, indexed in the order they're given. i.e. NewAlphabet("abcdefghijklmnñopqrstuvwxyz")
+ */
+func NewAlphabet(letters string) Alphabet {
+	alphabet := listAlphabet{indexes: make(map[rune]int)}
+
+	for _, letter := range letters {
+		if _, ok := alphabet.indexes[letter]; !ok {
+			alphabet.indexes[letter] = len(alphabet.letters)
+			alphabet.letters = append(alphabet.letters, letter)
+		}
+	}
+
+	return alphabet
+}
+
+type listAlphabet struct {
+	letters []rune
+	indexes map[rune]int
+}
+
+func (a listAlphabet) Index(letter rune) int {
+	if index, ok := a.indexes[letter]; ok {
+		return index
+	}
+	return -1
+}
+
+func (a listAlphabet) Letter(index int) rune {
+	return a.letters[index]
+}
+
+func (a listAlphabet) Size() int {
+	return len(a.letters)
+}
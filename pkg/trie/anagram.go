@@ -0,0 +1,147 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+)
+
+/**
+Canonical key shared by every anagram of a word, its letters in alphabet order, i.e. "ehllo" for hello
+ */
+func (details *WordDetails) AnagramKey(alphabet Alphabet) string {
+	var key strings.Builder
+
+	for _, letterCount := range details.SortedLetterCounts {
+		for i := 0; i < int(letterCount.Count); i++ {
+			key.WriteRune(alphabet.Letter(letterCount.Letter))
+		}
+	}
+
+	return key.String()
+}
+
+func sameCounts(a []LetterCount, b []LetterCount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+Every word in index using exactly the letters of key, which can be a canonical key or any word
+ */
+func Anagrams(index Index, alphabet Alphabet, key string) ([]*WordDetails, error) {
+	details, err := NewWordDetails(alphabet, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := index.Root()
+	for _, letterCount := range details.SortedLetterCounts {
+		var ok bool
+		if cursor, ok = index.Child(cursor, letterCount.Letter); !ok {
+			return nil, nil
+		}
+	}
+
+	// A node holds every word with the same distinct letters, only some of them have the same counts
+	var anagrams []*WordDetails
+	dictionary := index.Dictionary()
+	for _, i := range index.Words(cursor) {
+		word := &dictionary[i]
+		if sameCounts(word.SortedLetterCounts, details.SortedLetterCounts) && !containsWord(anagrams, word.Word) {
+			anagrams = append(anagrams, word)
+		}
+	}
+
+	return anagrams, nil
+}
+
+/**
+Whether words already has word, dictionaries can list a word more than once, or two spellings can be folded together
+ */
+func containsWord(words []*WordDetails, word string) bool {
+	for _, w := range words {
+		if w.Word == word {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+Words that are exact anagrams of each other
+ */
+type Family struct {
+	Key   string
+	Words []*WordDetails
+}
+
+func (f Family) Frequency() float64 {
+	total := 0.0
+	for _, word := range f.Words {
+		total += word.Frequency
+	}
+	return total
+}
+
+/**
+Every anagram family in index with at least minSize words, largest first then by total frequency and key
+ */
+func Families(index Index, alphabet Alphabet, minSize int) []Family {
+	var families []Family
+	dictionary := index.Dictionary()
+
+	var walk func(cursor Cursor)
+	walk = func(cursor Cursor) {
+		words := index.Words(cursor)
+		grouped := make([]bool, len(words))
+
+		for i := range words {
+			if grouped[i] {
+				continue
+			}
+
+			first := &dictionary[words[i]]
+			family := Family{Key: first.AnagramKey(alphabet)}
+			for j := i; j < len(words); j++ {
+				word := &dictionary[words[j]]
+				if !grouped[j] && sameCounts(first.SortedLetterCounts, word.SortedLetterCounts) {
+					grouped[j] = true
+					if !containsWord(family.Words, word.Word) {
+						family.Words = append(family.Words, word)
+					}
+				}
+			}
+
+			if len(family.Words) >= minSize {
+				families = append(families, family)
+			}
+		}
+
+		for letter := 0; letter < MaxPackedLetters; letter++ {
+			if child, ok := index.Child(cursor, letter); ok {
+				walk(child)
+			}
+		}
+	}
+	walk(index.Root())
+
+	sort.SliceStable(families, func(i, j int) bool {
+		a, b := families[i], families[j]
+		if len(a.Words) != len(b.Words) {
+			return len(a.Words) > len(b.Words)
+		}
+		if a.Frequency() != b.Frequency() {
+			return a.Frequency() > b.Frequency()
+		}
+		return a.Key < b.Key
+	})
+
+	return families
+}
@@ -0,0 +1,60 @@
+package trie
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAnagrams(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("stop\npots\ntops\nspot\npost\nspots\nopts\nsto\nhello\nhelo\nhole\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, _ := NewDAWG(&root, words)
+
+	for _, index := range []Index{flat, dawg} {
+		anagrams, err := Anagrams(index, English, "opst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(anagrams) != 6 {
+			t.Errorf("Anagram count was incorrect, got: %d, want: %d.", len(anagrams), 6)
+		}
+
+		anagrams, _ = Anagrams(index, English, "hole")
+		if len(anagrams) != 2 {
+			t.Errorf("Anagram count was incorrect, got: %d, want: %d.", len(anagrams), 2)
+		}
+
+		if anagrams, _ := Anagrams(index, English, "zzz"); len(anagrams) != 0 {
+			t.Errorf("Anagram count was incorrect, got: %d, want: %d.", len(anagrams), 0)
+		}
+
+		families := Families(index, English, 2)
+		if len(families) != 2 || families[0].Key != "opst" || families[1].Key != "ehlo" {
+			t.Errorf("Families were incorrect, got: %v.", families)
+		}
+	}
+
+	// café folds to cafe, which along with a repeated line shouldn't make a family
+	root, words, err = Read(context.Background(), strings.NewReader("cafe\ncafé\nface\nfade\nfade\n"), Options{Pipeline: DefaultPipeline(English)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, _ = NewFlatTrie(&root, words)
+
+	if anagrams, _ := Anagrams(flat, English, "cafe"); len(anagrams) != 2 {
+		t.Errorf("Anagram count was incorrect, got: %d, want: %d.", len(anagrams), 2)
+	}
+	if families := Families(flat, English, 2); len(families) != 1 || len(families[0].Words) != 2 {
+		t.Errorf("Families were incorrect, got: %v.", families)
+	}
+
+	hello := MustNewWordDetails(English, "hello")
+	if key := hello.AnagramKey(English); key != "ehllo" {
+		t.Errorf("Key was incorrect, got: %s, want: %s.", key, "ehllo")
+	}
+}
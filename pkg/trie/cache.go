@@ -0,0 +1,196 @@
+package trie
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+/**
+Where OpenCached keeps snapshots. Defaults to letter-combinations in the user's cache directory,
+or $LETTER_COMBINATIONS_CACHE when set. An empty CacheDir (or the variable set to "off") disables caching
+ */
+var CacheDir = defaultCacheDir()
+
+func defaultCacheDir() string {
+	if dir, ok := os.LookupEnv("LETTER_COMBINATIONS_CACHE"); ok {
+		if dir == "off" {
+			return ""
+		}
+		return dir
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "letter-combinations")
+}
+
+/**
+Identifies everything in Options that changes which words end up in the trie.
+Pipelines are identified by their stages' names and params
+ */
+func OptionsHash(opts Options) [32]byte {
+	hash := sha256.New()
+
+	alphabet := opts.Alphabet
+	if alphabet == nil {
+		alphabet = English
+	}
+	switch alphabet {
+	case English:
+		io.WriteString(hash, "alphabet:english\n")
+	case Unicode:
+		io.WriteString(hash, "alphabet:unicode\n")
+	default:
+		io.WriteString(hash, "alphabet:")
+		for i := 0; i < alphabet.Size(); i++ {
+			io.WriteString(hash, string(alphabet.Letter(i)))
+		}
+		io.WriteString(hash, "\n")
+	}
+
+	if opts.Pipeline != nil {
+		fmt.Fprintf(hash, "strict:%t\n", opts.Pipeline.Strict)
+		for _, stage := range opts.Pipeline.Stages {
+			fmt.Fprintf(hash, "stage:%s:%q\n", stage.Name, stage.Params)
+		}
+	}
+
+	var tags []string
+	for tag := range opts.TagSets {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		var words []string
+		for word, ok := range opts.TagSets[tag] {
+			if ok {
+				words = append(words, word)
+			}
+		}
+		sort.Strings(words)
+
+		fmt.Fprintf(hash, "tag:%s:%d\n", tag, len(words))
+		for _, word := range words {
+			io.WriteString(hash, word+"\n")
+		}
+	}
+
+	fmt.Fprintf(hash, "min-frequency:%s\n", strconv.FormatFloat(opts.MinFrequency, 'g', -1, 64))
+	fmt.Fprintf(hash, "tag:%s\n", opts.Tag)
+
+	var sum [32]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum
+}
+
+func HashFile(filename string) ([32]byte, error) {
+	var sum [32]byte
+
+	fileHandle, err := os.Open(filename)
+	if err != nil {
+		return sum, err
+	}
+	defer fileHandle.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, fileHandle); err != nil {
+		return sum, err
+	}
+
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+func snapshotPath(sourceHash, optionsHash [32]byte) string {
+	name := hex.EncodeToString(sourceHash[:8]) + "-" + hex.EncodeToString(optionsHash[:8]) + ".trie"
+	return filepath.Join(CacheDir, name)
+}
+
+/**
+Same as Open, but loads a snapshot from CacheDir when one exists for the same source file contents and options,
+otherwise builds the trie and saves a snapshot for next time. Problems with the cache are never fatal,
+the trie is just built from the source instead
+ */
+func OpenCached(ctx context.Context, filename string, opts Options) (Node, []WordDetails, error) {
+	if CacheDir == "" {
+		return Open(ctx, filename, opts)
+	}
+
+	sourceHash, err := HashFile(filename)
+	if err != nil {
+		return Node{}, nil, err
+	}
+	optionsHash := OptionsHash(opts)
+	path := snapshotPath(sourceHash, optionsHash)
+
+	if snapshot, err := loadSnapshotFile(path); err == nil &&
+		snapshot.SourceHash == sourceHash && snapshot.OptionsHash == optionsHash {
+		if opts.Pipeline != nil {
+			opts.Pipeline.Record(snapshot.Rejections)
+		}
+		return snapshot.Root, snapshot.Words, nil
+	}
+
+	root, words, err := Open(ctx, filename, opts)
+	if err != nil {
+		return root, words, err
+	}
+
+	alphabet := opts.Alphabet
+	if alphabet == nil {
+		alphabet = English
+	}
+
+	snapshot := &Snapshot{Root: root, Words: words, Alphabet: alphabet, SourceHash: sourceHash, OptionsHash: optionsHash}
+	if opts.Pipeline != nil {
+		snapshot.Rejections = opts.Pipeline.Rejections()
+	}
+	_ = saveSnapshotFile(path, snapshot)
+
+	return root, words, nil
+}
+
+func loadSnapshotFile(path string) (*Snapshot, error) {
+	fileHandle, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fileHandle.Close()
+
+	return LoadSnapshot(fileHandle)
+}
+
+/**
+Writes to a temporary file first so a concurrent reader never sees half a snapshot
+ */
+func saveSnapshotFile(path string, snapshot *Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := snapshot.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,135 @@
+package trie
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/normalise"
+)
+
+/**
+Points CacheDir at a directory that's removed once the test finishes, returning it
+ */
+func useTempCache(t *testing.T) string {
+	dir := t.TempDir()
+	previous := CacheDir
+	CacheDir = dir
+	t.Cleanup(func() {
+		CacheDir = previous
+	})
+	return dir
+}
+
+func writeDictionary(t *testing.T, dir string, contents string) string {
+	filename := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestOptionsHash(t *testing.T) {
+	abc := Options{Pipeline: normalise.New(normalise.OnlyLetters("abc"))}
+	abcd := Options{Pipeline: normalise.New(normalise.OnlyLetters("abcd"))}
+
+	if OptionsHash(abc) != OptionsHash(Options{Pipeline: normalise.New(normalise.OnlyLetters("abc"))}) {
+		t.Errorf("Expected the same options to hash the same")
+	}
+	if OptionsHash(abc) == OptionsHash(abcd) {
+		t.Errorf("Expected stages with different letters to hash differently")
+	}
+	if OptionsHash(abc) == OptionsHash(Options{Pipeline: normalise.New(normalise.OnlyLetters("abc")), MinFrequency: 1}) {
+		t.Errorf("Expected a different minimum frequency to hash differently")
+	}
+}
+
+func TestOpenCachedRejections(t *testing.T) {
+	filename := writeDictionary(t, useTempCache(t), "cat\ndon't\nx-ray\nact\n")
+
+	for _, run := range []string{"miss", "hit"} {
+		pipeline := normalise.English()
+		if _, _, err := OpenCached(context.Background(), filename, Options{Pipeline: pipeline}); err != nil {
+			t.Fatal(err)
+		}
+
+		if pipeline.Rejected() != 2 {
+			t.Errorf("Rejected count on a cache %s was incorrect, got: %d, want: %d.", run, pipeline.Rejected(), 2)
+		}
+	}
+}
+
+func TestOpenCached(t *testing.T) {
+	dir := useTempCache(t)
+	filename := writeDictionary(t, t.TempDir(), "cat\nact\n")
+	opts := Options{Pipeline: normalise.English()}
+
+	// A miss builds from the source and saves a snapshot
+	_, words, err := OpenCached(context.Background(), filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 2 {
+		t.Errorf("Word count on a miss was incorrect, got: %d, want: %d.", len(words), 2)
+	}
+
+	snapshots, _ := filepath.Glob(filepath.Join(dir, "*.trie"))
+	if len(snapshots) != 1 {
+		t.Fatalf("Snapshot count was incorrect, got: %d, want: %d.", len(snapshots), 1)
+	}
+
+	// Swap the words in the snapshot so a hit can be told apart from a rebuild
+	root, zebra, err := Read(context.Background(), strings.NewReader("zebra\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceHash, _ := HashFile(filename)
+	if err := saveSnapshotFile(snapshots[0], &Snapshot{Root: root, Words: zebra, SourceHash: sourceHash, OptionsHash: OptionsHash(opts)}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, words, err = OpenCached(context.Background(), filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 1 || words[0].Word != "zebra" {
+		t.Errorf("Words on a hit were incorrect, got: %v, want: %s.", words, "zebra")
+	}
+
+	// Changing the source leaves the old snapshot behind
+	writeDictionary(t, filepath.Dir(filename), "cat\nact\ntac\n")
+
+	_, words, err = OpenCached(context.Background(), filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 3 {
+		t.Errorf("Word count after the source changed was incorrect, got: %d, want: %d.", len(words), 3)
+	}
+
+	snapshots, _ = filepath.Glob(filepath.Join(dir, "*.trie"))
+	if len(snapshots) != 2 {
+		t.Errorf("Snapshot count was incorrect, got: %d, want: %d.", len(snapshots), 2)
+	}
+}
+
+func TestOpenCachedOff(t *testing.T) {
+	previous := CacheDir
+	CacheDir = ""
+	defer func() {
+		CacheDir = previous
+	}()
+
+	dir := t.TempDir()
+	filename := writeDictionary(t, dir, "cat\nact\n")
+
+	if _, words, err := OpenCached(context.Background(), filename, Options{}); err != nil || len(words) != 2 {
+		t.Errorf("Word count was incorrect, got: %d (%v), want: %d.", len(words), err, 2)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no snapshot to be written, got: %d files.", len(entries))
+	}
+}
@@ -0,0 +1,184 @@
+package trie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+/**
+A state of the automaton. Edges are stored next to each other in letter order starting at firstEdge, like flatNode.
+count is the number of final states reachable from here including this one
+ */
+type dawgState struct {
+	childMask uint32
+	firstEdge uint32
+	count     uint32
+	final     bool
+}
+
+/**
+skip is how many final states come before the target's subtree when walking from the edge's source,
+adding it up along a path gives the rank of the anagram group at the end of the path
+ */
+type dawgEdge struct {
+	target uint32
+	skip   uint32
+}
+
+/**
+A minimised acyclic automaton over sorted letter sequences. Nodes of a trie with the same words below them are
+merged into one state, so shared endings like "...rst" are only stored once. Because states are shared the
+words can't be stored on them, instead a Cursor's Rank counts the anagram groups passed on the way and is used
+to look the words up. Only works for alphabets of at most 32 letters
+ */
+type DAWG struct {
+	root   uint32
+	states []dawgState
+	edges  []dawgEdge
+
+	// Indexes into words grouped by node in depth first order, groups[i] is where the i-th group starts
+	wordIndexes []int32
+	groups      []uint32
+	words       []WordDetails
+}
+
+type dawgBuilder struct {
+	dawg     *DAWG
+	numbers  *wordNumbers
+	register map[string]uint32
+	key      []byte
+}
+
+/**
+words is the slice returned alongside root, which the nodes' words point into, see NewFlatTrie
+ */
+func NewDAWG(root *Node, words []WordDetails) (*DAWG, error) {
+	numbers, err := newWordNumbers(words)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := &dawgBuilder{
+		dawg:     &DAWG{words: words},
+		numbers:  numbers,
+		register: make(map[string]uint32),
+	}
+
+	id, err := builder.build(root)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.dawg.root = id
+	builder.dawg.groups = append(builder.dawg.groups, uint32(len(builder.dawg.wordIndexes)))
+
+	return builder.dawg, nil
+}
+
+/**
+Words are collected on the way down so groups are in the same order ranks count them, states are
+created on the way back up once all their children exist
+ */
+func (b *dawgBuilder) build(node *Node) (uint32, error) {
+	d := b.dawg
+	final := len(node.Words) > 0
+
+	if final {
+		d.groups = append(d.groups, uint32(len(d.wordIndexes)))
+		for _, word := range node.Words {
+			index, err := b.numbers.wordIndex(word)
+			if err != nil {
+				return 0, err
+			}
+			d.wordIndexes = append(d.wordIndexes, index)
+		}
+	}
+
+	letters := make([]int, 0, len(node.Children))
+	for letter := range node.Children {
+		if letter < 0 || letter >= 32 {
+			return 0, fmt.Errorf("letter %d doesn't fit in a DAWG, at most 32 letters are supported", letter)
+		}
+		letters = append(letters, letter)
+	}
+	sort.Ints(letters)
+
+	children := make([]uint32, len(letters))
+	for i, letter := range letters {
+		child, err := b.build(node.Children[letter])
+		if err != nil {
+			return 0, err
+		}
+		children[i] = child
+	}
+
+	// Two nodes are equivalent when they're both final or not and have the same letters to the same states
+	key := b.key[:0]
+	if final {
+		key = append(key, 1)
+	} else {
+		key = append(key, 0)
+	}
+	for i, letter := range letters {
+		key = append(key, byte(letter))
+		key = binary.AppendUvarint(key, uint64(children[i]))
+	}
+	b.key = key
+
+	if id, ok := b.register[string(key)]; ok {
+		return id, nil
+	}
+
+	state := dawgState{firstEdge: uint32(len(d.edges)), final: final}
+	if final {
+		state.count = 1
+	}
+	for i, letter := range letters {
+		state.childMask |= 1 << uint(letter)
+		d.edges = append(d.edges, dawgEdge{target: children[i], skip: state.count})
+		state.count += d.states[children[i]].count
+	}
+
+	id := uint32(len(d.states))
+	d.states = append(d.states, state)
+	b.register[string(key)] = id
+
+	return id, nil
+}
+
+func (d *DAWG) Root() Cursor {
+	return Cursor{Node: d.root}
+}
+
+func (d *DAWG) Child(cursor Cursor, letter int) (Cursor, bool) {
+	state := &d.states[cursor.Node]
+	bit := uint32(1) << uint(letter)
+
+	if state.childMask&bit == 0 {
+		return Cursor{}, false
+	}
+
+	edge := d.edges[state.firstEdge+uint32(bits.OnesCount32(state.childMask&(bit-1)))]
+	return Cursor{Node: edge.target, Rank: cursor.Rank + edge.skip}, true
+}
+
+func (d *DAWG) Words(cursor Cursor) []int32 {
+	if !d.states[cursor.Node].final {
+		return nil
+	}
+	return d.wordIndexes[d.groups[cursor.Rank]:d.groups[cursor.Rank+1]]
+}
+
+func (d *DAWG) Dictionary() []WordDetails {
+	return d.words
+}
+
+func (d *DAWG) StateCount() int {
+	return len(d.states)
+}
+
+func (d *DAWG) EdgeCount() int {
+	return len(d.edges)
+}
@@ -0,0 +1,150 @@
+package trie
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+/**
+Walks every path of both indexes together checking they have the same children and words everywhere
+ */
+func compareIndexes(t *testing.T, flat *FlatTrie, dawg *DAWG, flatCursor Cursor, dawgCursor Cursor, path []int) {
+	flatWords := flat.Words(flatCursor)
+	dawgWords := dawg.Words(dawgCursor)
+
+	if len(flatWords) != len(dawgWords) {
+		t.Fatalf("Word count at %v was incorrect, got: %d, want: %d.", path, len(dawgWords), len(flatWords))
+	}
+	for i := range flatWords {
+		if flatWords[i] != dawgWords[i] {
+			t.Fatalf("Word at %v was incorrect, got: %d, want: %d.", path, dawgWords[i], flatWords[i])
+		}
+	}
+
+	for letter := 0; letter < 32; letter++ {
+		flatChild, flatOk := flat.Child(flatCursor, letter)
+		dawgChild, dawgOk := dawg.Child(dawgCursor, letter)
+
+		if flatOk != dawgOk {
+			t.Fatalf("Child %d at %v was incorrect, got: %t, want: %t.", letter, path, dawgOk, flatOk)
+		}
+		if flatOk {
+			compareIndexes(t, flat, dawg, flatChild, dawgChild, append(path, letter))
+		}
+	}
+}
+
+func TestDAWG(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("cat\nact\ntac\nat\nzebra\nbraze\na\nfirst\nburst\nrst\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, err := NewDAWG(&root, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareIndexes(t, flat, dawg, flat.Root(), dawg.Root(), nil)
+
+	if dawg.StateCount() >= flat.NodeCount() {
+		t.Errorf("Expected fewer states than trie nodes, got: %d, trie: %d.", dawg.StateCount(), flat.NodeCount())
+	}
+
+	if _, err := NewDAWG(&Node{Children: map[int]*Node{'日': {}}}, nil); err == nil {
+		t.Errorf("Expected an error for a letter that doesn't fit")
+	}
+}
+
+func TestDAWGDictionary(t *testing.T) {
+	if _, err := os.Stat("../../3-to-9-letter-words.txt"); err != nil {
+		t.Skip("dictionary not found")
+	}
+
+	root, words, err := Open(context.Background(), "../../3-to-9-letter-words.txt", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, err := NewDAWG(&root, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareIndexes(t, flat, dawg, flat.Root(), dawg.Root(), nil)
+	t.Logf("trie nodes: %d, DAWG states: %d, edges: %d", flat.NodeCount(), dawg.StateCount(), dawg.EdgeCount())
+}
+
+/**
+How much the heap grows building index, after a collection on either side
+ */
+func heapGrowth(build func() any) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	index := build()
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(index)
+
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0
+	}
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+/**
+Reports the heap each index takes for the word list, the map trie includes the words the other two share
+ */
+func BenchmarkIndexMemory(b *testing.B) {
+	if _, err := os.Stat("../../3-to-9-letter-words.txt"); err != nil {
+		b.Skip("dictionary not found")
+	}
+
+	open := func() (Node, []WordDetails) {
+		root, words, err := Open(context.Background(), "../../3-to-9-letter-words.txt", Options{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		return root, words
+	}
+	root, words := open()
+
+	indexes := map[string]func() any{
+		"map": func() any {
+			root, words := open()
+			return []any{root, words}
+		},
+		"flat": func() any {
+			flat, err := NewFlatTrie(&root, words)
+			if err != nil {
+				b.Fatal(err)
+			}
+			return flat
+		},
+		"dawg": func() any {
+			dawg, err := NewDAWG(&root, words)
+			if err != nil {
+				b.Fatal(err)
+			}
+			return dawg
+		},
+	}
+
+	for _, name := range []string{"map", "flat", "dawg"} {
+		b.Run(name, func(b *testing.B) {
+			var bytes uint64
+			for n := 0; n < b.N; n++ {
+				bytes = heapGrowth(indexes[name])
+			}
+			b.ReportMetric(float64(bytes)/(1<<20), "MiB")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package trie
+
+import (
+	"context"
+	"flag"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/reader"
+)
+
+/**
+The dictionary filters every command takes, see AddDictionaryFlags
+ */
+type DictionaryFlags struct {
+	MinFrequency *float64
+	Tag          *string
+	CommonFile   *string
+}
+
+/**
+Registers -min-frequency, -tag and -common on flags
+ */
+func AddDictionaryFlags(flags *flag.FlagSet) DictionaryFlags {
+	return DictionaryFlags{
+		MinFrequency: flags.Float64("min-frequency", 0, "only use words with at least this frequency (TSV dictionaries)"),
+		Tag:          flags.String("tag", "", "only use words with this tag, i.e. common"),
+		CommonFile:   flags.String("common", "", "word list whose words are tagged common"),
+	}
+}
+
+/**
+Options for alphabet with its default pipeline and the flags' filters, reading the -common word list when it's set
+ */
+func (f DictionaryFlags) Options(ctx context.Context, alphabet Alphabet) (Options, error) {
+	opts := Options{
+		Alphabet:     alphabet,
+		Pipeline:     DefaultPipeline(alphabet),
+		MinFrequency: *f.MinFrequency,
+		Tag:          *f.Tag,
+	}
+
+	if *f.CommonFile != "" {
+		common, err := reader.ReadWordSet(ctx, *f.CommonFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.TagSets = map[string]map[string]bool{"common": common}
+	}
+
+	return opts, nil
+}
+
+/**
+Same as OpenCached, then logs whatever the pipeline rejected so no command drops lines silently
+ */
+func OpenDictionary(ctx context.Context, filename string, opts Options) (Node, []WordDetails, error) {
+	root, words, err := OpenCached(ctx, filename, opts)
+	if err != nil {
+		return root, words, err
+	}
+
+	if opts.Pipeline != nil {
+		LogRejections(filename, opts.Pipeline)
+	}
+
+	return root, words, nil
+}
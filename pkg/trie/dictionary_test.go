@@ -0,0 +1,34 @@
+package trie
+
+import (
+	"context"
+	"flag"
+	"testing"
+)
+
+func TestDictionaryFlags(t *testing.T) {
+	common := writeDictionary(t, useTempCache(t), "cat\n")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	dictionaryFlags := AddDictionaryFlags(flags)
+	if err := flags.Parse([]string{"-min-frequency", "2", "-tag", "common", "-common", common}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := dictionaryFlags.Options(context.Background(), English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.MinFrequency != 2 || opts.Tag != "common" || !opts.TagSets["common"]["cat"] {
+		t.Errorf("Options were incorrect, got: %+v.", opts)
+	}
+
+	filename := writeDictionary(t, t.TempDir(), "cat\t3\nact\t5\ntac\t1\n")
+	_, words, err := OpenDictionary(context.Background(), filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 1 || words[0].Word != "cat" {
+		t.Errorf("Words were incorrect, got: %v, want: %s.", words, "cat")
+	}
+}
@@ -0,0 +1,102 @@
+package trie
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+/**
+Children are stored next to each other in letter order starting at firstChild, childMask has a bit set for each
+letter that has a child so a child's position is the number of bits set below it
+ */
+type flatNode struct {
+	childMask  uint32
+	firstChild uint32
+	wordsStart uint32
+	wordsEnd   uint32
+}
+
+/**
+An immutable trie laid out in two contiguous arrays instead of a map per node. Words are kept as indexes into the
+words the trie was built with rather than copies. Only works for alphabets of at most 32 letters
+ */
+type FlatTrie struct {
+	nodes       []flatNode
+	wordIndexes []int32
+	words       []WordDetails
+}
+
+/**
+Lays root out breadth first so every node's children are contiguous. words is the slice returned alongside root,
+which the nodes' words point into
+ */
+func NewFlatTrie(root *Node, words []WordDetails) (*FlatTrie, error) {
+	numbers, err := newWordNumbers(words)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := &FlatTrie{nodes: []flatNode{{}}, words: words}
+	queue := []*Node{root}
+
+	for i := 0; i < len(queue); i++ {
+		node := queue[i]
+		var current flatNode
+
+		current.wordsStart = uint32(len(flat.wordIndexes))
+		for _, word := range node.Words {
+			index, err := numbers.wordIndex(word)
+			if err != nil {
+				return nil, err
+			}
+			flat.wordIndexes = append(flat.wordIndexes, index)
+		}
+		current.wordsEnd = uint32(len(flat.wordIndexes))
+
+		current.firstChild = uint32(len(flat.nodes))
+		for letter := range node.Children {
+			if letter < 0 || letter >= 32 {
+				return nil, fmt.Errorf("letter %d doesn't fit in a flat trie, at most 32 letters are supported", letter)
+			}
+			current.childMask |= 1 << uint(letter)
+		}
+		flat.nodes[i] = current
+
+		// Append children in letter order, which is the order of the bits in the mask
+		for mask := current.childMask; mask != 0; mask &= mask - 1 {
+			letter := bits.TrailingZeros32(mask)
+			queue = append(queue, node.Children[letter])
+			flat.nodes = append(flat.nodes, flatNode{})
+		}
+	}
+
+	return flat, nil
+}
+
+func (t *FlatTrie) Root() Cursor {
+	return Cursor{}
+}
+
+func (t *FlatTrie) Child(cursor Cursor, letter int) (Cursor, bool) {
+	node := &t.nodes[cursor.Node]
+	bit := uint32(1) << uint(letter)
+
+	if node.childMask&bit == 0 {
+		return Cursor{}, false
+	}
+
+	return Cursor{Node: node.firstChild + uint32(bits.OnesCount32(node.childMask&(bit-1)))}, true
+}
+
+func (t *FlatTrie) Words(cursor Cursor) []int32 {
+	node := &t.nodes[cursor.Node]
+	return t.wordIndexes[node.wordsStart:node.wordsEnd]
+}
+
+func (t *FlatTrie) Dictionary() []WordDetails {
+	return t.words
+}
+
+func (t *FlatTrie) NodeCount() int {
+	return len(t.nodes)
+}
@@ -0,0 +1,55 @@
+package trie
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFlatTrie(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("cat\nact\ntac\nat\nzebra\nbraze\na\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, err := NewFlatTrie(&root, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, word := range words {
+		cursor := flat.Root()
+		for _, letterCount := range word.SortedLetterCounts {
+			child, ok := flat.Child(cursor, letterCount.Letter)
+			if !ok {
+				t.Fatalf("Missing child %d for %s", letterCount.Letter, word.Word)
+			}
+			cursor = child
+		}
+
+		found := false
+		for _, i := range flat.Words(cursor) {
+			found = found || flat.Dictionary()[i].Word == word.Word
+		}
+		if !found {
+			t.Errorf("%s wasn't stored on its node", word.Word)
+		}
+	}
+
+	if _, ok := flat.Child(flat.Root(), English.Index('q')); ok {
+		t.Errorf("Found a child that doesn't exist")
+	}
+
+	if &flat.Dictionary()[0] != &words[0] {
+		t.Errorf("Expected the flat trie to share the words rather than copy them")
+	}
+
+	copied := append([]WordDetails(nil), words...)
+	if _, err := NewFlatTrie(&root, copied); err == nil {
+		t.Errorf("Expected an error for words the trie wasn't built with")
+	}
+
+	if _, err := NewFlatTrie(&Node{Children: map[int]*Node{'日': {}}}, nil); err == nil {
+		t.Errorf("Expected an error for a letter that doesn't fit")
+	}
+}
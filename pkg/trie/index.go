@@ -0,0 +1,59 @@
+package trie
+
+import (
+	"fmt"
+	"math"
+)
+
+/**
+A position in an Index. Node identifies the node, Rank is used by indexes that share nodes between different
+letter sequences (DAWG) to tell which sequence was taken to get there
+ */
+type Cursor struct {
+	Node uint32
+	Rank uint32
+}
+
+/**
+A read-only letter-multiset index walked one sorted letter at a time, implemented by FlatTrie and DAWG
+ */
+type Index interface {
+	Root() Cursor
+	// The cursor after taking letter from cursor, false if no word continues with that letter
+	Child(cursor Cursor, letter int) (Cursor, bool)
+	// Indexes into Dictionary of the words whose sorted letters are exactly the ones taken to reach cursor
+	Words(cursor Cursor) []int32
+	// The words the index was built over, shared with the caller rather than copied
+	Dictionary() []WordDetails
+}
+
+/**
+Numbers the words the nodes of a trie point to by their position in words, see wordIndex
+ */
+type wordNumbers struct {
+	words   []WordDetails
+	numbers map[*WordDetails]int32
+}
+
+func newWordNumbers(words []WordDetails) (*wordNumbers, error) {
+	if len(words) > math.MaxInt32 {
+		return nil, fmt.Errorf("%d words don't fit in an index, at most %d are supported", len(words), math.MaxInt32)
+	}
+
+	numbers := make(map[*WordDetails]int32, len(words))
+	for i := range words {
+		numbers[&words[i]] = int32(i)
+	}
+	return &wordNumbers{words, numbers}, nil
+}
+
+/**
+Where word is in words. Node words have to point into the slice returned alongside the trie, a copy can't be found
+ */
+func (n *wordNumbers) wordIndex(word *WordDetails) (int32, error) {
+	index, ok := n.numbers[word]
+	if !ok {
+		return 0, fmt.Errorf("%q isn't one of the trie's words, build indexes from the words the trie was returned with", word.Word)
+	}
+	return index, nil
+}
@@ -0,0 +1,104 @@
+package trie
+
+/**
+Letter counts packed 4 bits per letter, letters 0-15 in Lo and 16-31 in Hi. Each count uses the low 3 bits of its
+nibble, leaving the top bit free so Contains can compare every letter in one subtraction
+ */
+type PackedCounts struct {
+	Lo uint64
+	Hi uint64
+}
+
+const MaxPackedLetters = 32
+const MaxPackedCount = 7
+
+const packedGuard uint64 = 0x8888888888888888
+
+/**
+Packs counts, returning false if a letter index or count is too large to fit
+ */
+func PackCounts(counts []LetterCount) (PackedCounts, uint32, bool) {
+	var packed PackedCounts
+	var mask uint32
+
+	for _, letterCount := range counts {
+		if letterCount.Letter < 0 || letterCount.Letter >= MaxPackedLetters || letterCount.Count > MaxPackedCount {
+			return PackedCounts{}, 0, false
+		}
+
+		packed = packed.Add(letterCount.Letter, letterCount.Count)
+		mask |= 1 << uint(letterCount.Letter)
+	}
+
+	return packed, mask, true
+}
+
+/**
+Adds count to letter. The caller must keep the total at or below MaxPackedCount
+ */
+func (p PackedCounts) Add(letter int, count byte) PackedCounts {
+	if letter < 16 {
+		p.Lo += uint64(count) << (uint(letter) * 4)
+	} else {
+		p.Hi += uint64(count) << (uint(letter-16) * 4)
+	}
+	return p
+}
+
+func (p PackedCounts) Count(letter int) byte {
+	if letter < 16 {
+		return byte(p.Lo>>(uint(letter)*4)) & 0xf
+	}
+	return byte(p.Hi>>(uint(letter-16)*4)) & 0xf
+}
+
+/**
+Whether p has at least as many of every letter as other, i.e. other can be spelt from p.
+Setting the guard bit of every nibble means a nibble only loses it when other's count is larger, and never borrows
+from its neighbour
+ */
+func (p PackedCounts) Contains(other PackedCounts) bool {
+	return ((p.Lo|packedGuard)-other.Lo)&packedGuard == packedGuard &&
+		((p.Hi|packedGuard)-other.Hi)&packedGuard == packedGuard
+}
+
+/**
+Whether the word can be spelt using letters, falling back to comparing the letter counts when either couldn't be packed
+ */
+func (details *WordDetails) CanBeSpeltFrom(letters *WordDetails) bool {
+	if details.Packed && letters.Packed {
+		return letters.PackedCounts.Contains(details.PackedCounts)
+	}
+
+	return IsSubset(details.SortedLetterCounts, letters.SortedLetterCounts)
+}
+
+/**
+Whether every letter of subset appears at least as often in set, both sorted by letter
+ */
+func IsSubset(subset []LetterCount, set []LetterCount) bool {
+	i := -1
+
+	for j := 0; j < len(subset); j++ {
+		letterAndCount := subset[j]
+
+		// move the other words index along until we find a letter that matches
+		// returning false if we reach the end or the counts are incorrect
+		for {
+			i++
+			if i == len(set) {
+				return false
+			}
+
+			if letterAndCount.Letter == set[i].Letter {
+				if letterAndCount.Count <= set[i].Count {
+					break
+				} else {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomWord(random *rand.Rand) string {
+	letters := make([]rune, 1+random.Intn(9))
+	for i := range letters {
+		// A small alphabet so letters repeat and words often overlap
+		letters[i] = rune('a' + random.Intn(6))
+	}
+	return string(letters)
+}
+
+func TestPackedCountsMatchIsSubset(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		word := MustNewWordDetails(English, randomWord(random))
+		letters := MustNewWordDetails(English, randomWord(random))
+
+		if !word.Packed || !letters.Packed {
+			continue
+		}
+
+		want := IsSubset(word.SortedLetterCounts, letters.SortedLetterCounts)
+		if got := letters.PackedCounts.Contains(word.PackedCounts); got != want {
+			t.Fatalf("Contains(%s, %s) was incorrect, got: %t, want: %t.", letters.Word, word.Word, got, want)
+		}
+	}
+}
+
+func TestPackCountsLimits(t *testing.T) {
+	if _, _, ok := PackCounts([]LetterCount{{0, MaxPackedCount + 1}}); ok {
+		t.Errorf("Expected a count above MaxPackedCount not to pack")
+	}
+
+	if _, _, ok := PackCounts([]LetterCount{{MaxPackedLetters, 1}}); ok {
+		t.Errorf("Expected a letter beyond MaxPackedLetters not to pack")
+	}
+
+	word := MustNewWordDetails(English, "zzz")
+	if word.PackedCounts.Count(English.Index('z')) != 3 || word.LetterMask != 1<<25 {
+		t.Errorf("Packed z's were incorrect, got: %d, mask %b", word.PackedCounts.Count(25), word.LetterMask)
+	}
+
+	unpacked := MustNewWordDetails(Unicode, "abc")
+	if unpacked.Packed || !unpacked.CanBeSpeltFrom(&unpacked) {
+		t.Errorf("Expected unicode words to fall back to comparing letter counts")
+	}
+}
@@ -0,0 +1,246 @@
+package trie
+
+import (
+	"sort"
+)
+
+/**
+Which words to find in SubAnagrams. Letters and Required are counts per letter, i.e. the SortedLetterCounts of
+NewWordDetails, and don't need to be sorted
+ */
+type Query struct {
+	// Words may use each letter at most this many times
+	Letters []LetterCount
+
+	// Words must use each of these letters at least this many times
+	Required []LetterCount
+
+	// Number of blank tiles, each can stand for any one letter
+	Blanks int
+
+	// Length in letters, 0 for no limit
+	MinLength int
+	MaxLength int
+
+	// Stop after this many words, 0 for no limit
+	Limit int
+}
+
+/**
+A word found by a Query along with what its blanks stood for
+ */
+type Match struct {
+	Word *WordDetails
+
+	// Letters the blanks were used as, nil when the word didn't need any
+	Blanks []LetterCount
+}
+
+type subAnagramSearch struct {
+	index    Index
+	letters  []LetterCount
+	required []LetterCount
+	query    Query
+
+	// Letters the walk tries, either the query's letters or every letter when there are blanks
+	candidates []int
+	tiles      [MaxPackedLetters]int
+
+	packed  PackedCounts
+	canPack bool
+	found   int
+	yield   func(Match) bool
+	stopped bool
+}
+
+func sortedCounts(counts []LetterCount) []LetterCount {
+	sorted := append([]LetterCount(nil), counts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Letter < sorted[j].Letter
+	})
+	return sorted
+}
+
+func wordLength(word *WordDetails) int {
+	length := 0
+	for _, letterCount := range word.SortedLetterCounts {
+		length += int(letterCount.Count)
+	}
+	return length
+}
+
+/**
+Calls yield with every word in index that can be spelt from query's letters and blanks, in the index's order, until
+yield returns false or the limit is reached. The words point into the index and must not be modified
+ */
+func EachMatch(index Index, query Query, yield func(Match) bool) {
+	search := &subAnagramSearch{
+		index:    index,
+		letters:  sortedCounts(query.Letters),
+		required: sortedCounts(query.Required),
+		query:    query,
+		yield:    yield,
+	}
+	search.packed, _, search.canPack = PackCounts(search.letters)
+
+	for _, letterCount := range search.letters {
+		if letterCount.Letter >= 0 && letterCount.Letter < MaxPackedLetters {
+			search.tiles[letterCount.Letter] += int(letterCount.Count)
+		}
+	}
+
+	if query.Blanks > 0 {
+		for letter := 0; letter < MaxPackedLetters; letter++ {
+			search.candidates = append(search.candidates, letter)
+		}
+	} else {
+		for _, letterCount := range search.letters {
+			search.candidates = append(search.candidates, letterCount.Letter)
+		}
+	}
+
+	search.walk(index.Root(), 0, 0, 0, 0)
+}
+
+/**
+Every word and what its blanks stood for, see EachMatch
+ */
+func Matches(index Index, query Query) []Match {
+	var matches []Match
+
+	EachMatch(index, query, func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+
+	return matches
+}
+
+/**
+Like EachMatch without the blanks
+ */
+func EachSubAnagram(index Index, query Query, yield func(*WordDetails) bool) {
+	EachMatch(index, query, func(match Match) bool {
+		return yield(match.Word)
+	})
+}
+
+/**
+Every word in index that can be spelt from query's letters, see EachSubAnagram
+ */
+func SubAnagrams(index Index, query Query) []*WordDetails {
+	var words []*WordDetails
+
+	EachSubAnagram(index, query, func(word *WordDetails) bool {
+		words = append(words, word)
+		return true
+	})
+
+	return words
+}
+
+/**
+The letters blanks have to stand for to spell the word from letters (sorted by letter), false if it needs more than
+blanks of them
+ */
+func (details *WordDetails) Fill(letters []LetterCount, blanks int) ([]LetterCount, bool) {
+	var filled []LetterCount
+	needed := 0
+	j := 0
+
+	for _, letterCount := range details.SortedLetterCounts {
+		for j < len(letters) && letters[j].Letter < letterCount.Letter {
+			j++
+		}
+
+		var have byte
+		if j < len(letters) && letters[j].Letter == letterCount.Letter {
+			have = letters[j].Count
+		}
+
+		if letterCount.Count > have {
+			missing := letterCount.Count - have
+			needed += int(missing)
+			if needed > blanks {
+				return nil, false
+			}
+			filled = append(filled, LetterCount{letterCount.Letter, missing})
+		}
+	}
+
+	return filled, true
+}
+
+func (s *subAnagramSearch) match(word *WordDetails) (Match, bool) {
+	length := wordLength(word)
+	if length < s.query.MinLength || (s.query.MaxLength > 0 && length > s.query.MaxLength) {
+		return Match{}, false
+	}
+
+	if !IsSubset(s.required, word.SortedLetterCounts) {
+		return Match{}, false
+	}
+
+	if s.query.Blanks == 0 {
+		if s.canPack && word.Packed {
+			return Match{Word: word}, s.packed.Contains(word.PackedCounts)
+		}
+		return Match{Word: word}, IsSubset(word.SortedLetterCounts, s.letters)
+	}
+
+	filled, ok := word.Fill(s.letters, s.query.Blanks)
+	return Match{word, filled}, ok
+}
+
+/**
+Every letter of a path is distinct and in order, so depth is a lower bound on the length of the words below, each
+letter without a tile needs at least one blank, and once a required letter has been skipped over nothing below
+can contain it
+ */
+func (s *subAnagramSearch) walk(cursor Cursor, start int, required int, depth int, blanks int) {
+	if required == len(s.required) {
+		dictionary := s.index.Dictionary()
+		for _, i := range s.index.Words(cursor) {
+			match, ok := s.match(&dictionary[i])
+			if !ok {
+				continue
+			}
+
+			s.found++
+			if !s.yield(match) || (s.query.Limit > 0 && s.found >= s.query.Limit) {
+				s.stopped = true
+				return
+			}
+		}
+	}
+
+	if s.query.MaxLength > 0 && depth >= s.query.MaxLength {
+		return
+	}
+
+	for i := start; i < len(s.candidates) && !s.stopped; i++ {
+		letter := s.candidates[i]
+		nextRequired := required
+		nextBlanks := blanks
+
+		if letter >= 0 && letter < MaxPackedLetters && s.tiles[letter] == 0 {
+			nextBlanks++
+			if nextBlanks > s.query.Blanks {
+				continue
+			}
+		}
+
+		if required < len(s.required) {
+			if letter > s.required[required].Letter {
+				return
+			}
+			if letter == s.required[required].Letter {
+				nextRequired++
+			}
+		}
+
+		if child, ok := s.index.Child(cursor, letter); ok {
+			s.walk(child, i+1, nextRequired, depth+1, nextBlanks)
+		}
+	}
+}
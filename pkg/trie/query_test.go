@@ -0,0 +1,114 @@
+package trie
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSubAnagrams(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("then\nhen\nnet\nten\nthe\neh\ntenth\nhe\nat\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, _ := NewDAWG(&root, words)
+	letters := MustNewWordDetails(English, "then").SortedLetterCounts
+
+	cases := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"all", Query{Letters: letters}, "eh he hen net ten the then"},
+		{"required", Query{Letters: letters, Required: MustNewWordDetails(English, "n").SortedLetterCounts}, "hen net ten then"},
+		{"required twice", Query{Letters: letters, Required: MustNewWordDetails(English, "nn").SortedLetterCounts}, ""},
+		{"min length", Query{Letters: letters, MinLength: 4}, "then"},
+		{"max length", Query{Letters: letters, MaxLength: 2}, "eh he"},
+		{"limit", Query{Letters: letters, Limit: 3}, "3"},
+		{"no letters", Query{}, ""},
+	}
+
+	for _, index := range []Index{flat, dawg} {
+		for _, c := range cases {
+			var got []string
+			for _, word := range SubAnagrams(index, c.query) {
+				got = append(got, word.Word)
+			}
+
+			if c.name == "limit" {
+				if len(got) != 3 {
+					t.Errorf("%s was incorrect, got: %d, want: %d.", c.name, len(got), 3)
+				}
+				continue
+			}
+
+			sort.Strings(got)
+			if strings.Join(got, " ") != c.want {
+				t.Errorf("%s was incorrect, got: %v, want: %s.", c.name, got, c.want)
+			}
+		}
+	}
+}
+
+func TestEachSubAnagramStops(t *testing.T) {
+	root, words, _ := Read(context.Background(), strings.NewReader("then\nhen\nnet\nten\n"), Options{})
+	flat, _ := NewFlatTrie(&root, words)
+
+	calls := 0
+	EachSubAnagram(flat, Query{Letters: MustNewWordDetails(English, "then").SortedLetterCounts}, func(*WordDetails) bool {
+		calls++
+		return false
+	})
+
+	if calls != 1 {
+		t.Errorf("Calls was incorrect, got: %d, want: %d.", calls, 1)
+	}
+}
+
+func TestMatchesWithBlanks(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("then\nhen\nnet\nten\nthe\nteeth\nzebra\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, _ := NewDAWG(&root, words)
+
+	for _, index := range []Index{flat, dawg} {
+		got := make(map[string]string)
+		for _, match := range Matches(index, Query{Letters: MustNewWordDetails(English, "tn").SortedLetterCounts, Blanks: 2}) {
+			filled := ""
+			for _, letterCount := range match.Blanks {
+				filled += strings.Repeat(string(English.Letter(letterCount.Letter)), int(letterCount.Count))
+			}
+			got[match.Word.Word] = filled
+		}
+
+		want := map[string]string{"hen": "eh", "net": "e", "ten": "e", "the": "eh", "then": "eh"}
+		if len(got) != len(want) {
+			t.Errorf("Matches were incorrect, got: %v, want: %v.", got, want)
+		}
+		for word, filled := range want {
+			if got[word] != filled {
+				t.Errorf("Blanks for %s were incorrect, got: %q, want: %q.", word, got[word], filled)
+			}
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	word := MustNewWordDetails(English, "teeth")
+	letters := MustNewWordDetails(English, "eth").SortedLetterCounts
+
+	if _, ok := word.Fill(letters, 1); ok {
+		t.Errorf("Expected teeth to need more than 1 blank")
+	}
+
+	filled, ok := word.Fill(letters, 2)
+	if !ok || len(filled) != 2 || filled[0] != (LetterCount{English.Index('e'), 1}) || filled[1] != (LetterCount{English.Index('t'), 1}) {
+		t.Errorf("Fill was incorrect, got: %v, %t.", filled, ok)
+	}
+}
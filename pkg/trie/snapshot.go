@@ -0,0 +1,496 @@
+package trie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/normalise"
+)
+
+/**
+Snapshot layout, all integers are uvarints unless noted:
+
+	magic "LCTRIE" | version byte
+	source hash [32]byte | options hash [32]byte
+	alphabet kind byte | (custom alphabets) letter count, letters
+	node count
+	per node, breadth first from the root: child count, child letters in order
+	word count
+	per word: word length, word, node, letter count, (letter, count byte)...,
+		flags byte, (has frequency) frequency uint64 bits, (has tags) tag count, (tag length, tag)...
+	rejection count
+	per rejection: stage length, stage, reason length, reason, count
+	crc32 of everything above, 4 bytes big endian
+
+Nodes are numbered in the order they're stored, so a node's children are the next unclaimed nodes, the same layout
+NewFlatTrie uses. Loading only has to link the nodes back up and point each one at its words
+ */
+const snapshotMagic = "LCTRIE"
+const SnapshotVersion = 2
+
+const (
+	englishAlphabetKind byte = iota
+	unicodeAlphabetKind
+	customAlphabetKind
+)
+
+const (
+	hasFrequency byte = 1 << iota
+	hasTags
+)
+
+var ErrBadSnapshot = errors.New("not a trie snapshot")
+
+/**
+A trie and its words along with what they were built from
+ */
+type Snapshot struct {
+	Root     Node
+	Words    []WordDetails
+	Alphabet Alphabet
+
+	// SHA-256 of the source dictionary and of the Options used to build it, see OptionsHash
+	SourceHash  [32]byte
+	OptionsHash [32]byte
+
+	// What the pipeline rejected while building the trie, so loading it can still report them
+	Rejections []normalise.Rejection
+}
+
+type snapshotWriter struct {
+	w   *bufio.Writer
+	crc hash.Hash32
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (sw *snapshotWriter) write(data []byte) {
+	if sw.err != nil {
+		return
+	}
+	sw.crc.Write(data)
+	_, sw.err = sw.w.Write(data)
+}
+
+func (sw *snapshotWriter) uvarint(value uint64) {
+	n := binary.PutUvarint(sw.buf[:], value)
+	sw.write(sw.buf[:n])
+}
+
+func (sw *snapshotWriter) string(value string) {
+	sw.uvarint(uint64(len(value)))
+	sw.write([]byte(value))
+}
+
+/**
+The trie's nodes breadth first from the root, children in letter order
+ */
+func (s *Snapshot) nodes() []*Node {
+	nodes := []*Node{&s.Root}
+
+	for i := 0; i < len(nodes); i++ {
+		for _, letter := range childLetters(nodes[i]) {
+			nodes = append(nodes, nodes[i].Children[letter])
+		}
+	}
+
+	return nodes
+}
+
+func childLetters(node *Node) []int {
+	letters := make([]int, 0, len(node.Children))
+	for letter := range node.Children {
+		letters = append(letters, letter)
+	}
+	sort.Ints(letters)
+	return letters
+}
+
+func (s *Snapshot) Save(w io.Writer) error {
+	nodes := s.nodes()
+	numbers := make(map[*Node]int, len(nodes))
+	for i, node := range nodes {
+		numbers[node] = i
+	}
+
+	// The node a word is on is the one reached by walking its letters
+	wordNodes := make([]int, len(s.Words))
+	for i, word := range s.Words {
+		node := &s.Root
+		for _, letterCount := range word.SortedLetterCounts {
+			if node = node.Children[letterCount.Letter]; node == nil {
+				return fmt.Errorf("%q isn't in the trie", word.Word)
+			}
+		}
+		wordNodes[i] = numbers[node]
+	}
+
+	sw := &snapshotWriter{w: bufio.NewWriter(w), crc: crc32.NewIEEE()}
+
+	sw.write([]byte(snapshotMagic))
+	sw.write([]byte{SnapshotVersion})
+	sw.write(s.SourceHash[:])
+	sw.write(s.OptionsHash[:])
+
+	switch s.Alphabet {
+	case English, nil:
+		sw.write([]byte{englishAlphabetKind})
+	case Unicode:
+		sw.write([]byte{unicodeAlphabetKind})
+	default:
+		sw.write([]byte{customAlphabetKind})
+		sw.uvarint(uint64(s.Alphabet.Size()))
+		for i := 0; i < s.Alphabet.Size(); i++ {
+			sw.uvarint(uint64(s.Alphabet.Letter(i)))
+		}
+	}
+
+	sw.uvarint(uint64(len(nodes)))
+	for _, node := range nodes {
+		letters := childLetters(node)
+		sw.uvarint(uint64(len(letters)))
+		for _, letter := range letters {
+			sw.uvarint(uint64(letter))
+		}
+	}
+
+	sw.uvarint(uint64(len(s.Words)))
+
+	for i, word := range s.Words {
+		sw.string(word.Word)
+		sw.uvarint(uint64(wordNodes[i]))
+
+		sw.uvarint(uint64(len(word.SortedLetterCounts)))
+		for _, letterCount := range word.SortedLetterCounts {
+			sw.uvarint(uint64(letterCount.Letter))
+			sw.write([]byte{letterCount.Count})
+		}
+
+		var flags byte
+		if word.Frequency != 0 {
+			flags |= hasFrequency
+		}
+		if len(word.Tags) > 0 {
+			flags |= hasTags
+		}
+		sw.write([]byte{flags})
+
+		if flags&hasFrequency != 0 {
+			var frequency [8]byte
+			binary.BigEndian.PutUint64(frequency[:], math.Float64bits(word.Frequency))
+			sw.write(frequency[:])
+		}
+
+		if flags&hasTags != 0 {
+			sw.uvarint(uint64(len(word.Tags)))
+			for _, tag := range word.Tags {
+				sw.string(tag)
+			}
+		}
+	}
+
+	sw.uvarint(uint64(len(s.Rejections)))
+	for _, rejection := range s.Rejections {
+		sw.string(rejection.Stage)
+		sw.string(rejection.Reason)
+		sw.uvarint(uint64(rejection.Count))
+	}
+
+	if sw.err != nil {
+		return sw.err
+	}
+
+	var checksum [4]byte
+	binary.BigEndian.PutUint32(checksum[:], sw.crc.Sum32())
+	if _, err := sw.w.Write(checksum[:]); err != nil {
+		return err
+	}
+
+	return sw.w.Flush()
+}
+
+type snapshotReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+}
+
+func (sr *snapshotReader) ReadByte() (byte, error) {
+	b, err := sr.r.ReadByte()
+	if err == nil {
+		sr.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (sr *snapshotReader) read(data []byte) error {
+	if _, err := io.ReadFull(sr.r, data); err != nil {
+		return err
+	}
+	sr.crc.Write(data)
+	return nil
+}
+
+func (sr *snapshotReader) uvarint() (uint64, error) {
+	return binary.ReadUvarint(sr)
+}
+
+func (sr *snapshotReader) string() (string, error) {
+	length, err := sr.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if length > 1<<20 {
+		return "", fmt.Errorf("%w: string of %d bytes", ErrBadSnapshot, length)
+	}
+
+	data := make([]byte, length)
+	err = sr.read(data)
+	return string(data), err
+}
+
+func readSnapshotHeader(sr *snapshotReader, s *Snapshot) error {
+	magic := make([]byte, len(snapshotMagic))
+	if err := sr.read(magic); err != nil || string(magic) != snapshotMagic {
+		return ErrBadSnapshot
+	}
+
+	version, err := sr.ReadByte()
+	if err != nil {
+		return err
+	}
+	if version != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadSnapshot, version)
+	}
+
+	if err := sr.read(s.SourceHash[:]); err != nil {
+		return err
+	}
+	return sr.read(s.OptionsHash[:])
+}
+
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	sr := &snapshotReader{r: bufio.NewReader(r), crc: crc32.NewIEEE()}
+	s := &Snapshot{}
+
+	err := readSnapshotHeader(sr, s)
+	if err == nil {
+		err = readSnapshotBody(sr, s)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: truncated", ErrBadSnapshot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expected := sr.crc.Sum32()
+
+	var checksum [4]byte
+	if _, err := io.ReadFull(sr.r, checksum[:]); err != nil {
+		return nil, fmt.Errorf("%w: missing checksum", ErrBadSnapshot)
+	}
+	if binary.BigEndian.Uint32(checksum[:]) != expected {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrBadSnapshot)
+	}
+
+	return s, nil
+}
+
+func readSnapshotBody(sr *snapshotReader, s *Snapshot) error {
+	kind, err := sr.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case englishAlphabetKind:
+		s.Alphabet = English
+	case unicodeAlphabetKind:
+		s.Alphabet = Unicode
+	case customAlphabetKind:
+		size, err := sr.uvarint()
+		if err != nil {
+			return err
+		}
+
+		var letters bytes.Buffer
+		for i := uint64(0); i < size; i++ {
+			letter, err := sr.uvarint()
+			if err != nil {
+				return err
+			}
+			letters.WriteRune(rune(letter))
+		}
+		s.Alphabet = NewAlphabet(letters.String())
+	default:
+		return fmt.Errorf("%w: unknown alphabet %d", ErrBadSnapshot, kind)
+	}
+
+	nodes, err := readSnapshotNodes(sr, &s.Root, s.Alphabet)
+	if err != nil {
+		return err
+	}
+
+	wordCount, err := sr.uvarint()
+	if err != nil {
+		return err
+	}
+
+	// Don't trust the counts for allocations, a corrupt file would otherwise ask for any amount of memory
+	s.Words = make([]WordDetails, 0, min(wordCount, 1<<20))
+	var wordNodes []*Node
+
+	for i := uint64(0); i < wordCount; i++ {
+		text, err := sr.string()
+		if err != nil {
+			return err
+		}
+		word := WordDetails{Word: text}
+
+		node, err := sr.uvarint()
+		if err != nil {
+			return err
+		}
+		if node >= uint64(len(nodes)) {
+			return fmt.Errorf("%w: %q is on node %d of %d", ErrBadSnapshot, text, node, len(nodes))
+		}
+		wordNodes = append(wordNodes, nodes[node])
+
+		letterCount, err := sr.uvarint()
+		if err != nil {
+			return err
+		}
+		word.SortedLetterCounts = make([]LetterCount, 0, min(letterCount, 1<<10))
+
+		for j := uint64(0); j < letterCount; j++ {
+			letter, err := sr.uvarint()
+			if err != nil {
+				return err
+			}
+			count, err := sr.ReadByte()
+			if err != nil {
+				return err
+			}
+			if letter >= uint64(s.Alphabet.Size()) {
+				return fmt.Errorf("%w: %q has letter %d, which is not in the alphabet", ErrBadSnapshot, text, letter)
+			}
+			word.SortedLetterCounts = append(word.SortedLetterCounts, LetterCount{int(letter), count})
+		}
+		word.PackedCounts, word.LetterMask, word.Packed = PackCounts(word.SortedLetterCounts)
+
+		flags, err := sr.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if flags&hasFrequency != 0 {
+			var frequency [8]byte
+			if err := sr.read(frequency[:]); err != nil {
+				return err
+			}
+			word.Frequency = math.Float64frombits(binary.BigEndian.Uint64(frequency[:]))
+		}
+
+		if flags&hasTags != 0 {
+			tagCount, err := sr.uvarint()
+			if err != nil {
+				return err
+			}
+			for j := uint64(0); j < tagCount; j++ {
+				tag, err := sr.string()
+				if err != nil {
+					return err
+				}
+				word.Tags = append(word.Tags, tag)
+			}
+		}
+
+		s.Words = append(s.Words, word)
+	}
+
+	// Only point nodes at the words once they've all been read, appending moves them
+	for i := range s.Words {
+		wordNodes[i].Words = append(wordNodes[i].Words, &s.Words[i])
+	}
+
+	rejectionCount, err := sr.uvarint()
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < rejectionCount; i++ {
+		var rejection normalise.Rejection
+
+		if rejection.Stage, err = sr.string(); err != nil {
+			return err
+		}
+		if rejection.Reason, err = sr.string(); err != nil {
+			return err
+		}
+		count, err := sr.uvarint()
+		if err != nil {
+			return err
+		}
+		rejection.Count = int(count)
+
+		s.Rejections = append(s.Rejections, rejection)
+	}
+
+	return nil
+}
+
+/**
+Reads the nodes written by Save into root, returning every node in the order they were stored
+ */
+func readSnapshotNodes(sr *snapshotReader, root *Node, alphabet Alphabet) ([]*Node, error) {
+	nodeCount, err := sr.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if nodeCount == 0 {
+		return nil, fmt.Errorf("%w: no root node", ErrBadSnapshot)
+	}
+
+	*root = Node{make(map[int]*Node), []*WordDetails{}}
+	nodes := make([]*Node, 1, min(nodeCount, 1<<20))
+	nodes[0] = root
+
+	for i := 0; i < len(nodes); i++ {
+		childCount, err := sr.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(nodes))+childCount > nodeCount {
+			return nil, fmt.Errorf("%w: more than %d nodes", ErrBadSnapshot, nodeCount)
+		}
+
+		for j := uint64(0); j < childCount; j++ {
+			letter, err := sr.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			if letter >= uint64(alphabet.Size()) {
+				return nil, fmt.Errorf("%w: node letter %d is not in the alphabet", ErrBadSnapshot, letter)
+			}
+
+			child := &Node{make(map[int]*Node), []*WordDetails{}}
+			nodes[i].Children[int(letter)] = child
+			nodes = append(nodes, child)
+		}
+	}
+
+	if uint64(len(nodes)) != nodeCount {
+		return nil, fmt.Errorf("%w: %d nodes, expected %d", ErrBadSnapshot, len(nodes), nodeCount)
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,95 @@
+package trie
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/joeyciechanowicz/letter-combinations/pkg/normalise"
+)
+
+const snapshotWords = "cat\nact\t12.5\tcommon,animal\ntac\nzebra\n"
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, alphabet := range []Alphabet{English, Unicode, NewAlphabet("zyxwvutsrqponmlkjihgfedcba")} {
+		root, words, err := Read(context.Background(), strings.NewReader(snapshotWords), Options{Alphabet: alphabet})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original := &Snapshot{Root: root, Words: words, Alphabet: alphabet, SourceHash: [32]byte{1, 2, 3},
+			Rejections: []normalise.Rejection{{Stage: "only-letters", Reason: "contains '-'", Count: 2}}}
+
+		var buf bytes.Buffer
+		if err := original.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadSnapshot(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if loaded.SourceHash != original.SourceHash {
+			t.Errorf("Source hash was incorrect, got: %x, want: %x.", loaded.SourceHash, original.SourceHash)
+		}
+
+		if len(loaded.Rejections) != 1 || loaded.Rejections[0] != original.Rejections[0] {
+			t.Errorf("Rejections were incorrect, got: %v, want: %v.", loaded.Rejections, original.Rejections)
+		}
+
+		if len(loaded.Words) != len(words) {
+			t.Fatalf("Word count was incorrect, got: %d, want: %d.", len(loaded.Words), len(words))
+		}
+
+		for i, word := range words {
+			got := loaded.Words[i]
+			if got.Word != word.Word || got.Frequency != word.Frequency || strings.Join(got.Tags, ",") != strings.Join(word.Tags, ",") ||
+				!sameCounts(got.SortedLetterCounts, word.SortedLetterCounts) || got.PackedCounts != word.PackedCounts {
+				t.Errorf("Word was incorrect, got: %+v, want: %+v.", got, word)
+			}
+		}
+
+		node := &loaded.Root
+		for _, letterCount := range MustNewWordDetails(alphabet, "act").SortedLetterCounts {
+			node = node.Children[letterCount.Letter]
+		}
+		if len(node.Words) != 3 {
+			t.Errorf("Trie was incorrect, got: %d words for act, want: %d.", len(node.Words), 3)
+		}
+
+		if nodes, want := len(loaded.nodes()), len(original.nodes()); nodes != want {
+			t.Errorf("Node count was incorrect, got: %d, want: %d.", nodes, want)
+		}
+	}
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("cat\nact\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&Snapshot{Root: root, Words: words}).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-8] ^= 0xff
+	if _, err := LoadSnapshot(bytes.NewReader(flipped)); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("Expected a bad snapshot error for a flipped byte, got: %v", err)
+	}
+
+	if _, err := LoadSnapshot(bytes.NewReader(data[:len(data)/2])); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("Expected a bad snapshot error for a truncated file, got: %v", err)
+	}
+
+	if _, err := LoadSnapshot(strings.NewReader("cat\nact\n")); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("Expected a bad snapshot error for a word list, got: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package trie
+
+/**
+Which words to find in Superanagrams
+ */
+type SuperQuery struct {
+	// Words must use each letter at least this many times
+	Letters []LetterCount
+
+	// Most letters a word can have beyond Letters, -1 for no limit and 0 for exact anagrams
+	MaxExtra int
+
+	// Length in letters, 0 for no limit
+	MinLength int
+	MaxLength int
+
+	// Stop after this many words, 0 for no limit
+	Limit int
+}
+
+type superanagramSearch struct {
+	index   Index
+	letters []LetterCount
+	query   SuperQuery
+	length  int
+	found   int
+	yield   func(*WordDetails) bool
+	stopped bool
+}
+
+/**
+Calls yield with every word in index containing all of query's letters, in the index's order, until yield returns
+false or the limit is reached. The words point into the index and must not be modified
+ */
+func EachSuperanagram(index Index, query SuperQuery, yield func(*WordDetails) bool) {
+	search := &superanagramSearch{
+		index:   index,
+		letters: sortedCounts(query.Letters),
+		query:   query,
+		yield:   yield,
+	}
+
+	for _, letterCount := range search.letters {
+		search.length += int(letterCount.Count)
+	}
+
+	search.walk(index.Root(), 0, 0, 0, 0)
+}
+
+/**
+Every word in index containing all of query's letters, see EachSuperanagram
+ */
+func Superanagrams(index Index, query SuperQuery) []*WordDetails {
+	var words []*WordDetails
+
+	EachSuperanagram(index, query, func(word *WordDetails) bool {
+		words = append(words, word)
+		return true
+	})
+
+	return words
+}
+
+func (s *superanagramSearch) matches(word *WordDetails) bool {
+	length := wordLength(word)
+
+	if length < s.query.MinLength || (s.query.MaxLength > 0 && length > s.query.MaxLength) {
+		return false
+	}
+	if s.query.MaxExtra >= 0 && length-s.length > s.query.MaxExtra {
+		return false
+	}
+
+	return IsSubset(s.letters, word.SortedLetterCounts)
+}
+
+/**
+Like the sub-anagram walk but the other way round, any letter can be taken and it's the query's letters that can't
+be skipped. Every letter outside the query adds at least one extra letter to the words below
+ */
+func (s *superanagramSearch) walk(cursor Cursor, from int, next int, depth int, extra int) {
+	if next == len(s.letters) {
+		dictionary := s.index.Dictionary()
+		for _, i := range s.index.Words(cursor) {
+			if !s.matches(&dictionary[i]) {
+				continue
+			}
+
+			s.found++
+			if !s.yield(&dictionary[i]) || (s.query.Limit > 0 && s.found >= s.query.Limit) {
+				s.stopped = true
+				return
+			}
+		}
+	}
+
+	if s.query.MaxLength > 0 && depth >= s.query.MaxLength {
+		return
+	}
+
+	for letter := from; letter < MaxPackedLetters && !s.stopped; letter++ {
+		nextLetter := next
+		nextExtra := extra
+
+		if next < len(s.letters) && letter > s.letters[next].Letter {
+			return
+		}
+
+		if next < len(s.letters) && letter == s.letters[next].Letter {
+			nextLetter++
+		} else {
+			nextExtra++
+			if s.query.MaxExtra >= 0 && nextExtra > s.query.MaxExtra {
+				continue
+			}
+		}
+
+		if child, ok := s.index.Child(cursor, letter); ok {
+			s.walk(child, letter+1, nextLetter, depth+1, nextExtra)
+		}
+	}
+}
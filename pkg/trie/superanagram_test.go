@@ -0,0 +1,49 @@
+package trie
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSuperanagrams(t *testing.T) {
+	root, words, err := Read(context.Background(), strings.NewReader("cat\nact\ncats\nscatter\ntact\nchat\nat\ndog\ncatcalls\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, _ := NewFlatTrie(&root, words)
+	dawg, _ := NewDAWG(&root, words)
+	letters := MustNewWordDetails(English, "cat").SortedLetterCounts
+
+	cases := []struct {
+		name  string
+		query SuperQuery
+		want  string
+	}{
+		{"all", SuperQuery{Letters: letters, MaxExtra: -1}, "act cat catcalls cats chat scatter tact"},
+		{"max extra", SuperQuery{Letters: letters, MaxExtra: 1}, "act cat cats chat tact"},
+		{"no extra", SuperQuery{Letters: letters, MaxExtra: 0}, "act cat"},
+		{"length", SuperQuery{Letters: letters, MaxExtra: -1, MinLength: 4, MaxLength: 7}, "cats chat scatter tact"},
+		{"repeated", SuperQuery{Letters: MustNewWordDetails(English, "tt").SortedLetterCounts, MaxExtra: -1}, "scatter tact"},
+	}
+
+	for _, index := range []Index{flat, dawg} {
+		for _, c := range cases {
+			var got []string
+			for _, word := range Superanagrams(index, c.query) {
+				got = append(got, word.Word)
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, " ") != c.want {
+				t.Errorf("%s was incorrect, got: %v, want: %s.", c.name, got, c.want)
+			}
+		}
+
+		if got := Superanagrams(index, SuperQuery{Letters: letters, MaxExtra: -1, Limit: 2}); len(got) != 2 {
+			t.Errorf("Limit was incorrect, got: %d, want: %d.", len(got), 2)
+		}
+	}
+}
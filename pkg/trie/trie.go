@@ -0,0 +1,262 @@
+package trie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/normalise"
+	"github.com/joeyciechanowicz/letter-combinations/pkg/reader"
+	"io"
+	"log"
+	"sort"
+)
+
+type LetterCount struct {
+	Letter int
+	Count  byte
+}
+
+type WordDetails struct {
+	Word               string
+	SortedLetterCounts []LetterCount
+	Frequency          float64
+	Tags               []string
+
+	// Bit per letter present and the packed counts, only set when Packed, see PackCounts
+	LetterMask   uint32
+	PackedCounts PackedCounts
+	Packed       bool
+}
+
+func (details *WordDetails) HasTag(tag string) bool {
+	for _, t := range details.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+type WordDetailsSlice []WordDetails
+
+type Node struct {
+	Children map[int]*Node
+	Words    []*WordDetails
+}
+
+/**
+Counts the letters of word, sorted by their index in alphabet.
+Returns an error if a letter isn't in the alphabet
+ */
+func NewWordDetails(alphabet Alphabet, word string) (WordDetails, error) {
+	var details = WordDetails{
+		Word:               word,
+		SortedLetterCounts: []LetterCount{},
+	}
+
+	var indexes []int
+	for _, letter := range word {
+		index := alphabet.Index(letter)
+		if index < 0 {
+			return details, fmt.Errorf("%q contains %q which is not in the alphabet", word, letter)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		last := len(details.SortedLetterCounts) - 1
+
+		if last >= 0 && details.SortedLetterCounts[last].Letter == index {
+			details.SortedLetterCounts[last].Count++
+		} else {
+			details.SortedLetterCounts = append(details.SortedLetterCounts, LetterCount{index, 1})
+		}
+	}
+
+	details.PackedCounts, details.LetterMask, details.Packed = PackCounts(details.SortedLetterCounts)
+
+	return details, nil
+}
+
+/**
+Like NewWordDetails but panics if a letter isn't in the alphabet
+ */
+func MustNewWordDetails(alphabet Alphabet, word string) WordDetails {
+	details, err := NewWordDetails(alphabet, word)
+	if err != nil {
+		panic(err)
+	}
+	return details
+}
+
+/**
+Controls how dictionary lines become WordDetails
+ */
+type Options struct {
+	// Defaults to English
+	Alphabet Alphabet
+
+	// Words are passed through Pipeline before being inserted, nil inserts them as-is
+	Pipeline *normalise.Pipeline
+
+	// Words in a set are given the set's key as a tag, i.e. {"common": words}
+	TagSets map[string]map[string]bool
+
+	// Words with a lower frequency or without the tag (when set) are left out
+	MinFrequency float64
+	Tag          string
+}
+
+/**
+The normalisation used by Create, English lists are folded down to a-z while anything else keeps all letters
+ */
+func DefaultPipeline(alphabet Alphabet) *normalise.Pipeline {
+	if alphabet == English {
+		return normalise.English()
+	}
+	return normalise.Unicode()
+}
+
+/**
+Creates a trie of WordDetails keyed by the alphabet index of each letter, with words stored on the node
+reached by walking their sorted letters. Uses a cached snapshot when there is one, see OpenCached.
+Exits the program on any error
+ */
+func Create(filename string, alphabet Alphabet) (Node, []WordDetails) {
+	trie, words, err := OpenDictionary(context.Background(), filename, Options{Alphabet: alphabet, Pipeline: DefaultPipeline(alphabet)})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return trie, words
+}
+
+func LogRejections(filename string, pipeline *normalise.Pipeline) {
+	for _, rejection := range pipeline.Rejections() {
+		log.Printf("%s: %s rejected %d words, %s", filename, rejection.Stage, rejection.Count, rejection.Reason)
+	}
+}
+
+/**
+Same as Create but returns an error instead of exiting.
+Lines can be plain words or TSV entries, see reader.ParseEntry
+ */
+func Open(ctx context.Context, filename string, opts Options) (Node, []WordDetails, error) {
+	builder := newTreeBuilder(opts)
+
+	if err := reader.ReadFileContext(ctx, filename, builder.addLine); err != nil {
+		return Node{}, nil, err
+	}
+
+	return builder.finish()
+}
+
+/**
+Creates the trie from a dictionary read from r, see Open
+ */
+func Read(ctx context.Context, r io.Reader, opts Options) (Node, []WordDetails, error) {
+	builder := newTreeBuilder(opts)
+
+	if err := reader.Read(ctx, r, builder.addLine); err != nil {
+		return Node{}, nil, err
+	}
+
+	return builder.finish()
+}
+
+type treeBuilder struct {
+	opts      Options
+	trie      Node
+	words     []WordDetails
+	nodeCount int
+}
+
+func newTreeBuilder(opts Options) *treeBuilder {
+	if opts.Alphabet == nil {
+		opts.Alphabet = English
+	}
+
+	return &treeBuilder{
+		opts: opts,
+		trie: Node{
+			make(map[int]*Node),
+			make([]*WordDetails, 0),
+		},
+	}
+}
+
+func (b *treeBuilder) addLine(line string) error {
+	entry, err := reader.ParseEntry(line)
+	if err != nil {
+		return err
+	}
+
+	add := func(word string) error {
+		entry.Word = word
+		return b.add(entry)
+	}
+
+	if b.opts.Pipeline == nil {
+		return add(entry.Word)
+	}
+	return b.opts.Pipeline.Filter(add)(entry.Word)
+}
+
+func (b *treeBuilder) add(entry reader.Entry) error {
+	word := entry.Word
+
+	if word == "" {
+		return errors.New("blank word")
+	}
+
+	details, err := NewWordDetails(b.opts.Alphabet, word)
+	if err != nil {
+		return err
+	}
+	details.Frequency = entry.Frequency
+	details.Tags = entry.Tags
+
+	for tag, set := range b.opts.TagSets {
+		if set[word] && !details.HasTag(tag) {
+			details.Tags = append(details.Tags, tag)
+		}
+	}
+
+	if details.Frequency < b.opts.MinFrequency || (b.opts.Tag != "" && !details.HasTag(b.opts.Tag)) {
+		return nil
+	}
+
+	b.words = append(b.words, details)
+	return nil
+}
+
+/**
+Inserts the words once they've all been read, so the nodes point into the returned slice rather than at copies
+ */
+func (b *treeBuilder) finish() (Node, []WordDetails, error) {
+	for i := range b.words {
+		b.insert(&b.words[i])
+	}
+	return b.trie, b.words, nil
+}
+
+func (b *treeBuilder) insert(details *WordDetails) {
+	var head *Node
+	head = &b.trie
+
+	for _, letterCount := range details.SortedLetterCounts {
+		if _, ok := head.Children[letterCount.Letter]; !ok {
+			b.nodeCount++
+
+			head.Children[letterCount.Letter] = &Node{
+				make(map[int]*Node),
+				[]*WordDetails{},
+			}
+		}
+
+		head = head.Children[letterCount.Letter]
+	}
+
+	head.Words = append(head.Words, details)
+}
@@ -0,0 +1,73 @@
+package trie
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewWordDetails(t *testing.T) {
+	details, err := NewWordDetails(English, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []LetterCount{{English.Index('e'), 1}, {English.Index('h'), 1}, {English.Index('l'), 2}, {English.Index('o'), 1}}
+	if len(details.SortedLetterCounts) != len(want) {
+		t.Fatalf("Letter counts were incorrect, got: %v, want: %v.", details.SortedLetterCounts, want)
+	}
+	for i := range want {
+		if details.SortedLetterCounts[i] != want[i] {
+			t.Errorf("Letter counts were incorrect, got: %v, want: %v.", details.SortedLetterCounts, want)
+		}
+	}
+
+	if _, err := NewWordDetails(English, "don't"); err == nil {
+		t.Errorf("Expected an error for a letter outside the alphabet")
+	}
+
+	if details, err := NewWordDetails(English, ""); err != nil || len(details.SortedLetterCounts) != 0 {
+		t.Errorf("Expected no letter counts for an empty word, got: %v, %v", details.SortedLetterCounts, err)
+	}
+}
+
+func TestReadWithAlphabets(t *testing.T) {
+	words := "año\nano\nnao\n"
+
+	cases := []struct {
+		name     string
+		alphabet Alphabet
+		anagrams int
+	}{
+		// año is folded to ano
+		{"english", English, 3},
+		{"unicode", Unicode, 2},
+		{"spanish", NewAlphabet("abcdefghijklmnñopqrstuvwxyz"), 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pipeline := DefaultPipeline(c.alphabet)
+			if c.alphabet != English {
+				pipeline = nil
+			}
+
+			root, details, err := Read(context.Background(), strings.NewReader(words), Options{Alphabet: c.alphabet, Pipeline: pipeline})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(details) != 3 {
+				t.Errorf("Word count was incorrect, got: %d, want: %d.", len(details), 3)
+			}
+
+			node := &root
+			for _, letter := range []rune("ano") {
+				node = node.Children[c.alphabet.Index(letter)]
+			}
+			if len(node.Words) != c.anagrams {
+				t.Errorf("Anagrams weren't grouped, got: %d words, want: %d.", len(node.Words), c.anagrams)
+			}
+		})
+	}
+}